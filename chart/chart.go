@@ -0,0 +1,213 @@
+// Package chart generates the Litmus chart manifests (ChaosExperiment, ChaosEngine, RBAC and ChartServiceVersion) for
+// the registered experiments from their detail structs.
+package chart
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/jaconi-io/litmus/environment"
+	"github.com/jaconi-io/litmus/experiments"
+	"github.com/kelseyhightower/envconfig"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// Files generated per experiment and the templates used to generate them. The name of the chart service version file
+// depends on the experiment and is added separately.
+var experimentFiles = map[string]string{
+	"experiment.yaml":          "experiment.yaml.tmpl",
+	"engine.yaml":              "engine.yaml.tmpl",
+	"iam-service-account.yaml": "iam-service-account.yaml.tmpl",
+	"rbac.yaml":                "rbac.yaml.tmpl",
+}
+
+// Namespace the charts are installed into. The engine, experiment, RBAC resources and the Workload Identity member of
+// the IAM service account must all refer to the same namespace.
+const Namespace = "default"
+
+// Functions available in templates.
+var funcs = template.FuncMap{
+	// lowerFirst converts the first letter of a description to lower case (e.g. to use it within a sentence).
+	"lowerFirst": func(s string) string {
+		if s == "" {
+			return s
+		}
+
+		return strings.ToLower(s[:1]) + s[1:]
+	},
+}
+
+// Env is an environment variable of an experiment.
+type Env struct {
+	Name        string
+	Value       string
+	Description string
+	Required    bool
+}
+
+// Experiment is the data available to the experiment templates.
+type Experiment struct {
+	Name        string
+	Namespace   string
+	Description string
+	Keywords    []string
+	Env         []Env
+}
+
+// Generate writes the charts for all given experiments into dir. Each experiment gets its own directory. The category
+// chart service version and package are written into dir directly.
+func Generate(dir string, definitions []experiments.Definition) error {
+	files, err := Render(definitions)
+	if err != nil {
+		return err
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Render the charts for all given experiments. The files are returned by their path relative to the chart directory.
+func Render(definitions []experiments.Definition) (map[string][]byte, error) {
+	tmpl, err := template.New("").Funcs(funcs).ParseFS(templates, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, d := range definitions {
+		data, err := experiment(d)
+		if err != nil {
+			return nil, err
+		}
+
+		names := map[string]string{d.Name + ".chartserviceversion.yaml": "chartserviceversion.yaml.tmpl"}
+		for name, t := range experimentFiles {
+			names[name] = t
+		}
+
+		for name, t := range names {
+			content, err := execute(tmpl, t, data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render %s for experiment %q: %w", name, d.Name, err)
+			}
+
+			files[filepath.Join(d.Name, name)] = content
+		}
+	}
+
+	for name, t := range map[string]string{
+		"gcp.chartserviceversion.yaml": "category.chartserviceversion.yaml.tmpl",
+		"gcp.package.yaml":             "package.yaml.tmpl",
+	} {
+		content, err := execute(tmpl, t, definitions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", name, err)
+		}
+
+		files[name] = content
+	}
+
+	return files, nil
+}
+
+func execute(tmpl *template.Template, name string, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// experiment builds the template data for an experiment definition.
+func experiment(d experiments.Definition) (Experiment, error) {
+	common, err := variables(&environment.ExperimentDetails{})
+	if err != nil {
+		return Experiment{}, err
+	}
+
+	commonKeys := map[string]bool{}
+	for _, v := range common {
+		commonKeys[v.Key] = true
+	}
+
+	all, err := variables(d.Details)
+	if err != nil {
+		return Experiment{}, fmt.Errorf("failed to inspect details of experiment %q: %w", d.Name, err)
+	}
+
+	// Experiment specific variables come first, followed by common variables marked for charts.
+	var specific, shared []Env
+	for _, v := range all {
+		env := Env{Name: v.Key, Value: v.Default, Description: v.Description, Required: v.Required}
+		if value, ok := d.Defaults[v.Key]; ok {
+			env.Value = value
+		}
+
+		if !commonKeys[v.Key] {
+			specific = append(specific, env)
+		} else if v.Chart {
+			shared = append(shared, env)
+		}
+	}
+
+	return Experiment{
+		Name:        d.Name,
+		Namespace:   Namespace,
+		Description: d.Description,
+		Keywords:    strings.Split(d.Name, "-"),
+		Env:         append(specific, shared...),
+	}, nil
+}
+
+// variable is an environment variable as understood by envconfig.
+type variable struct {
+	Key         string
+	Default     string
+	Description string
+	Required    bool
+	Chart       bool
+}
+
+// variables lists the environment variables of a detail struct in field order. The variables are gathered by
+// envconfig itself, so the keys match the ones used when processing the environment.
+func variables(details interface{}) ([]variable, error) {
+	var vars []variable
+	tmpl := template.Must(template.New("variables").Funcs(template.FuncMap{
+		"collect": func(key string, tags reflect.StructTag) string {
+			vars = append(vars, variable{
+				Key:         key,
+				Default:     tags.Get("default"),
+				Description: tags.Get("desc"),
+				Required:    tags.Get("required") == "true",
+				Chart:       tags.Get("chart") == "true",
+			})
+			return ""
+		},
+	}).Parse(`{{ range . }}{{ collect .Key .Tags }}{{ end }}`))
+
+	if err := envconfig.Usaget("", details, ioutil.Discard, tmpl); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
@@ -0,0 +1,102 @@
+package chart_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	. "github.com/jaconi-io/litmus/chart"
+	"github.com/jaconi-io/litmus/environment"
+	"github.com/jaconi-io/litmus/experiments"
+	"github.com/stretchr/testify/assert"
+)
+
+// Regenerate the golden files with: go test ./chart -update
+var update = flag.Bool("update", false, "update golden files")
+
+// Directory containing the charts checked into the repository.
+const chartsDir = "../charts/gcp"
+
+type testDetails struct {
+	environment.ExperimentDetails
+	Additional string `desc:"An additional value" required:"true"`
+	Optional   int    `default:"42" desc:"An optional value" split_words:"true"`
+}
+
+// Make sure the charts in the repository are in sync with the registered experiments.
+func TestGolden(t *testing.T) {
+	if *update {
+		assert.NoError(t, Generate(chartsDir, experiments.Experiments.Definitions()))
+	}
+
+	files, err := Render(experiments.Experiments.Definitions())
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		golden, err := ioutil.ReadFile(filepath.Join(chartsDir, name))
+		if assert.NoError(t, err, "missing chart file %s; run go test ./chart -update", name) {
+			assert.Equal(t, string(golden), string(files[name]), "chart file %s is out of sync; run go test ./chart -update", name)
+		}
+	}
+}
+
+// Make sure environment variables are derived from the details struct.
+func TestRender(t *testing.T) {
+	files, err := Render([]experiments.Definition{{
+		Name:        "test-experiment",
+		Description: "Test an experiment",
+		Details:     &testDetails{},
+		Defaults:    map[string]string{"OPTIONAL": "21"},
+	}})
+	assert.NoError(t, err)
+
+	assert.Contains(t, files, "gcp.package.yaml")
+	assert.Contains(t, files, "gcp.chartserviceversion.yaml")
+	assert.Contains(t, files, "test-experiment/experiment.yaml")
+	assert.Contains(t, files, "test-experiment/engine.yaml")
+	assert.Contains(t, files, "test-experiment/rbac.yaml")
+	assert.Contains(t, files, "test-experiment/test-experiment.chartserviceversion.yaml")
+
+	engine := string(files["test-experiment/engine.yaml"])
+	assert.Contains(t, engine, "            # An additional value. Required.\n            - name: ADDITIONAL\n              value: \"\"\n")
+	assert.Contains(t, engine, "            # An optional value.\n            - name: OPTIONAL\n              value: \"21\"\n")
+	assert.NotContains(t, engine, "CHAOS_NAMESPACE")
+
+	experiment := string(files["test-experiment/experiment.yaml"])
+	assert.Contains(t, experiment, "      - name: ADDITIONAL\n        value: \"\"\n")
+	assert.Contains(t, experiment, "      - name: TOTAL_CHAOS_DURATION\n        value: \"\"\n")
+}
+
+// Make sure all manifests of an experiment refer to the same namespace, so the engine finds the service account and the
+// Workload Identity member matches it.
+func TestRenderNamespace(t *testing.T) {
+	files, err := Render([]experiments.Definition{{
+		Name:        "test-experiment",
+		Description: "Test an experiment",
+		Details:     &testDetails{},
+	}})
+	assert.NoError(t, err)
+
+	for _, name := range []string{"engine.yaml", "experiment.yaml", "iam-service-account.yaml", "rbac.yaml"} {
+		content := string(files["test-experiment/"+name])
+		assert.Contains(t, content, "  namespace: "+Namespace+"\n", name)
+		assert.Equal(t, strings.Count(content, "namespace:"), strings.Count(content, "namespace: "+Namespace+"\n"), name)
+	}
+
+	assert.Contains(t, string(files["test-experiment/iam-service-account.yaml"]), "svc.id.goog["+Namespace+"/test-experiment-sa]")
+}
+
+// Make sure an error is returned, if the details are not a struct pointer.
+func TestRenderInvalidDetails(t *testing.T) {
+	_, err := Render([]experiments.Definition{{Name: "test-experiment", Details: testDetails{}}})
+	assert.EqualError(t, err, "failed to inspect details of experiment \"test-experiment\": specification must be a struct pointer")
+}
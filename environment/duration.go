@@ -0,0 +1,39 @@
+package environment
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Duration can be configured in integer seconds (e.g. 60) like in upstream Litmus, or in Go duration syntax (e.g.
+// 1m30s). An empty value is a zero duration.
+type Duration struct {
+	time.Duration
+}
+
+// Decode implements envconfig.Decoder.
+func (d *Duration) Decode(value string) error {
+	if value == "" {
+		d.Duration = 0
+		return nil
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		d.Duration = time.Duration(seconds) * time.Second
+	} else if d.Duration, err = time.ParseDuration(value); err != nil {
+		return fmt.Errorf("invalid duration %q; expected seconds (e.g. 60) or a duration (e.g. 1m)", value)
+	}
+
+	if d.Duration < 0 {
+		return fmt.Errorf("invalid duration %q; must not be negative", value)
+	}
+
+	return nil
+}
+
+// seconds converts a duration into whole seconds, as expected by litmus. Fractions of a second are rounded up, so a
+// positive duration is never converted to zero.
+func seconds(d Duration) int {
+	return int((d.Duration + time.Second - 1) / time.Second)
+}
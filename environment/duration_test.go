@@ -0,0 +1,37 @@
+package environment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationDecode(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"":      0,
+		"0":     0,
+		"60":    time.Minute,
+		"90s":   90 * time.Second,
+		"1m30s": 90 * time.Second,
+		"10ms":  10 * time.Millisecond,
+	} {
+		d := Duration{}
+		assert.NoError(t, d.Decode(value), value)
+		assert.Equal(t, expected, d.Duration, value)
+	}
+}
+
+func TestDurationDecodeInvalid(t *testing.T) {
+	for _, value := range []string{"foo", "1.5", "-1", "-1m"} {
+		d := Duration{}
+		assert.Error(t, d.Decode(value), value)
+	}
+}
+
+func TestSeconds(t *testing.T) {
+	assert.Equal(t, 0, seconds(Duration{}))
+	assert.Equal(t, 1, seconds(Duration{10 * time.Millisecond}))
+	assert.Equal(t, 2, seconds(Duration{1500 * time.Millisecond}))
+	assert.Equal(t, 90, seconds(Duration{90 * time.Second}))
+}
@@ -1,27 +1,36 @@
 package environment
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
-	"time"
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/litmuschaos/litmus-go/pkg/types"
+	k8sTypes "k8s.io/apimachinery/pkg/types"
 )
 
 // ExperimentDetails can be used as an embedded struct by the specific experiment details.
 type ExperimentDetails struct {
-	AppAnnotationCheck bool          `default:"false" envconfig:"ANNOTATION_CHECK"`
-	AppAnnotationKey   string        `default:"litmuschaos.io/chaos" envconfig:"ANNOTATION_KEY"`
-	AppAnnotationValue string        `default:"true" envconfig:"ANNOTATION_VALUE"`
-	AppKind            string        `split_words:"true"`
-	AppLabel           string        `split_words:"true"`
-	AppNamespace       string        `split_words:"true"`
-	ChaosDuration      time.Duration `split_words:"true"`
-	ChaosNamespace     string        `default:"litmus" split_words:"true"`
-	EngineName         string        `envconfig:"CHAOS_ENGINE"`
-	ExperimentName     string        `split_words:"true"`
-	JobCleanupPolicy   string        `default:"retain" split_words:"true"`
+	AppAnnotationCheck bool     `default:"false" envconfig:"ANNOTATION_CHECK"`
+	AppAnnotationKey   string   `default:"litmuschaos.io/chaos" envconfig:"ANNOTATION_KEY"`
+	AppAnnotationValue string   `default:"true" envconfig:"ANNOTATION_VALUE"`
+	AppKind            string   `split_words:"true"`
+	AppLabel           string   `split_words:"true"`
+	AppNamespace       string   `split_words:"true"`
+	ChaosDuration      Duration `chart:"true" desc:"Duration of the chaos in seconds (e.g. 60) or as duration (e.g. 1m)" envconfig:"TOTAL_CHAOS_DURATION"`
+	ChaosInterval      Duration `envconfig:"CHAOS_INTERVAL"`
+	ChaosNamespace     string   `default:"litmus" split_words:"true"`
+	ChaosPodName       string   `envconfig:"POD_NAME"`
+	ChaosUID           string   `envconfig:"CHAOS_UID"`
+	DryRun             bool     `default:"false" split_words:"true"`
+	EngineName         string   `envconfig:"CHAOS_ENGINE"`
+	ExperimentName     string   `split_words:"true"`
+	ExperimentTimeout  Duration `split_words:"true"`
+	JobCleanupPolicy   string   `default:"retain" split_words:"true"`
+	RampTime           Duration `chart:"true" default:"0" desc:"Period to wait before and after the chaos in seconds (e.g. 30) or as duration (e.g. 30s)" envconfig:"RAMP_TIME"`
+	StatusCheckDelay   Duration `default:"2" envconfig:"DELAY"`
+	StatusCheckTimeout Duration `default:"180" envconfig:"TIMEOUT"`
 }
 
 // Populate chaos, experiment and result details using environment variables.
@@ -31,16 +40,9 @@ func Populate(experimentName string, chaos *types.ChaosDetails, experiment inter
 		return err
 	}
 
-	elem := reflect.ValueOf(experiment).Elem()
-	field := elem.FieldByName("ExperimentDetails")
-
-	if !field.IsValid() {
-		return fmt.Errorf("ExperimentDetails is mising; make sure %T is embedded into %T", &ExperimentDetails{}, experiment)
-	}
-
-	converted, ok := field.Interface().(ExperimentDetails)
-	if !ok {
-		return fmt.Errorf("could not convert %s to %T; make sure ExperimentDetails has the correct type", field.Type(), &ExperimentDetails{})
+	converted, err := Lookup(experiment)
+	if err != nil {
+		return err
 	}
 
 	// Fallback to actual experiment name, if none has been set.
@@ -57,12 +59,36 @@ func Populate(experimentName string, chaos *types.ChaosDetails, experiment inter
 	appDetails.Namespace = converted.AppNamespace
 
 	chaos.AppDetail = appDetails
-	chaos.ChaosDuration = int(converted.ChaosDuration)
+	chaos.ChaosDuration = seconds(converted.ChaosDuration)
 	chaos.ChaosNamespace = converted.ChaosNamespace
+	chaos.ChaosPodName = converted.ChaosPodName
+	chaos.ChaosUID = k8sTypes.UID(converted.ChaosUID)
 	chaos.EngineName = converted.EngineName
 	chaos.ExperimentName = converted.ExperimentName
 	chaos.JobCleanupPolicy = converted.JobCleanupPolicy
+	chaos.Delay = seconds(converted.StatusCheckDelay)
+	chaos.Timeout = seconds(converted.StatusCheckTimeout)
 
 	types.SetResultAttributes(result, *chaos)
 	return nil
 }
+
+// Lookup the ExperimentDetails embedded into the specific experiment details.
+func Lookup(experiment interface{}) (ExperimentDetails, error) {
+	value := reflect.ValueOf(experiment)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return ExperimentDetails{}, errors.New("specification must be a struct pointer")
+	}
+
+	field := value.Elem().FieldByName("ExperimentDetails")
+	if !field.IsValid() {
+		return ExperimentDetails{}, fmt.Errorf("ExperimentDetails is mising; make sure %T is embedded into %T", &ExperimentDetails{}, experiment)
+	}
+
+	converted, ok := field.Interface().(ExperimentDetails)
+	if !ok {
+		return ExperimentDetails{}, fmt.Errorf("could not convert %s to %T; make sure ExperimentDetails has the correct type", field.Type(), &ExperimentDetails{})
+	}
+
+	return converted, nil
+}
@@ -3,6 +3,7 @@ package environment_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	. "github.com/jaconi-io/litmus/environment"
 	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
@@ -43,6 +44,19 @@ func TestPopulateMissingAdditional(t *testing.T) {
 	assert.EqualError(t, err, "required key ADDITIONAL missing value")
 }
 
+// Make sure the embedded ExperimentDetails are returned.
+func TestLookup(t *testing.T) {
+	details, err := Lookup(&testDetails{ExperimentDetails: ExperimentDetails{ExperimentName: "foo"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", details.ExperimentName)
+}
+
+// Make sure an error is returned, if the experiment details are not a struct pointer.
+func TestLookupNoPointer(t *testing.T) {
+	_, err := Lookup(testDetails{})
+	assert.EqualError(t, err, "specification must be a struct pointer")
+}
+
 func TestPopulateDefaults(t *testing.T) {
 	defer tmpEnv(map[string]string{
 		"ADDITIONAL": "",
@@ -60,8 +74,15 @@ func TestPopulateDefaults(t *testing.T) {
 	assert.Equal(t, "", experiment.AppKind)
 	assert.Equal(t, "", experiment.AppLabel)
 	assert.Equal(t, "", experiment.AppNamespace)
+	assert.Equal(t, time.Duration(0), experiment.ChaosDuration.Duration)
+	assert.Equal(t, time.Duration(0), experiment.ChaosInterval.Duration)
 	assert.Equal(t, "litmus", experiment.ChaosNamespace)
+	assert.Equal(t, false, experiment.DryRun)
 	assert.Equal(t, "", experiment.ExperimentName)
+	assert.Equal(t, time.Duration(0), experiment.ExperimentTimeout.Duration)
+	assert.Equal(t, time.Duration(0), experiment.RampTime.Duration)
+	assert.Equal(t, 2*time.Second, experiment.StatusCheckDelay.Duration)
+	assert.Equal(t, 3*time.Minute, experiment.StatusCheckTimeout.Duration)
 
 	assert.Equal(t, false, chaos.AppDetail.AnnotationCheck)
 	assert.Equal(t, "litmuschaos.io/chaos", chaos.AppDetail.AnnotationKey)
@@ -74,7 +95,7 @@ func TestPopulateDefaults(t *testing.T) {
 	assert.Equal(t, "litmus", chaos.ChaosNamespace)
 	assert.Equal(t, "", chaos.ChaosPodName)
 	assert.Equal(t, k8sTypes.UID(""), chaos.ChaosUID)
-	assert.Equal(t, 0, chaos.Delay)
+	assert.Equal(t, 2, chaos.Delay)
 	assert.Equal(t, "", chaos.EngineName)
 	assert.Equal(t, "test", chaos.ExperimentName)
 	assert.Equal(t, "", chaos.InstanceID)
@@ -83,23 +104,31 @@ func TestPopulateDefaults(t *testing.T) {
 	assert.Equal(t, "", chaos.ProbeImagePullPolicy)
 	assert.Equal(t, false, chaos.Randomness)
 	assert.Equal(t, []v1alpha1.TargetDetails(nil), chaos.Targets)
-	assert.Equal(t, 0, chaos.Timeout)
+	assert.Equal(t, 180, chaos.Timeout)
 }
 
 func TestPopulate(t *testing.T) {
 	defer tmpEnv(map[string]string{
-		"ADDITIONAL":         "foo",
-		"ANNOTATION_CHECK":   "true",
-		"ANNOTATION_KEY":     "foo",
-		"ANNOTATION_VALUE":   "bar",
-		"APP_KIND":           "deployment",
-		"APP_LABEL":          "foo=bar",
-		"APP_NAMESPACE":      "default",
-		"CHAOS_DURATION":     "30m",
-		"CHAOS_NAMESPACE":    "chaos",
-		"CHAOS_ENGINE":       "foo",
-		"EXPERIMENT_NAME":    "foo",
-		"JOB_CLEANUP_POLICY": "delete",
+		"ADDITIONAL":           "foo",
+		"ANNOTATION_CHECK":     "true",
+		"ANNOTATION_KEY":       "foo",
+		"ANNOTATION_VALUE":     "bar",
+		"APP_KIND":             "deployment",
+		"APP_LABEL":            "foo=bar",
+		"APP_NAMESPACE":        "default",
+		"CHAOS_INTERVAL":       "10",
+		"DELAY":                "5s",
+		"RAMP_TIME":            "1m",
+		"TIMEOUT":              "60",
+		"TOTAL_CHAOS_DURATION": "30m",
+		"CHAOS_NAMESPACE":      "chaos",
+		"CHAOS_ENGINE":         "foo",
+		"CHAOS_UID":            "1234",
+		"DRY_RUN":              "true",
+		"EXPERIMENT_NAME":      "foo",
+		"EXPERIMENT_TIMEOUT":   "3600",
+		"JOB_CLEANUP_POLICY":   "delete",
+		"POD_NAME":             "foo-runner",
 	})()
 
 	chaos := &types.ChaosDetails{}
@@ -114,9 +143,16 @@ func TestPopulate(t *testing.T) {
 	assert.Equal(t, "deployment", experiment.AppKind)
 	assert.Equal(t, "foo=bar", experiment.AppLabel)
 	assert.Equal(t, "default", experiment.AppNamespace)
+	assert.Equal(t, 30*time.Minute, experiment.ChaosDuration.Duration)
+	assert.Equal(t, 10*time.Second, experiment.ChaosInterval.Duration)
 	assert.Equal(t, "chaos", experiment.ChaosNamespace)
+	assert.Equal(t, true, experiment.DryRun)
 	assert.Equal(t, "foo", experiment.EngineName)
 	assert.Equal(t, "foo", experiment.ExperimentName)
+	assert.Equal(t, time.Hour, experiment.ExperimentTimeout.Duration)
+	assert.Equal(t, time.Minute, experiment.RampTime.Duration)
+	assert.Equal(t, 5*time.Second, experiment.StatusCheckDelay.Duration)
+	assert.Equal(t, time.Minute, experiment.StatusCheckTimeout.Duration)
 
 	assert.Equal(t, true, chaos.AppDetail.AnnotationCheck)
 	assert.Equal(t, "foo", chaos.AppDetail.AnnotationKey)
@@ -125,11 +161,11 @@ func TestPopulate(t *testing.T) {
 	assert.Equal(t, "foo=bar", chaos.AppDetail.Label)
 	assert.Equal(t, "default", chaos.AppDetail.Namespace)
 
-	assert.Equal(t, 1800000000000, chaos.ChaosDuration)
+	assert.Equal(t, 1800, chaos.ChaosDuration)
 	assert.Equal(t, "chaos", chaos.ChaosNamespace)
-	// assert.NotEqual(t, "", chaos.ChaosPodName)
-	// assert.NotEqual(t, k8sTypes.UID(""), chaos.ChaosUID)
-	// assert.NotEqual(t, 0, chaos.Delay)
+	assert.Equal(t, "foo-runner", chaos.ChaosPodName)
+	assert.Equal(t, k8sTypes.UID("1234"), chaos.ChaosUID)
+	assert.Equal(t, 5, chaos.Delay)
 	assert.Equal(t, "foo", chaos.EngineName)
 	// assert.NotEqual(t, "foo", chaos.ExperimentName)
 	// assert.NotEqual(t, "", chaos.InstanceID)
@@ -138,7 +174,7 @@ func TestPopulate(t *testing.T) {
 	// assert.NotEqual(t, "", chaos.ProbeImagePullPolicy)
 	// assert.NotEqual(t, false, chaos.Randomness)
 	// assert.NotEqual(t, []v1alpha1.TargetDetails(nil), chaos.Targets)
-	// assert.NotEqual(t, 0, chaos.Timeout)
+	assert.Equal(t, 60, chaos.Timeout)
 }
 
 func tmpEnv(env map[string]string) func() {
@@ -0,0 +1,159 @@
+package experiments
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	clients "github.com/litmuschaos/litmus-go/pkg/clients"
+	"github.com/litmuschaos/litmus-go/pkg/events"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/result"
+	"github.com/litmuschaos/litmus-go/pkg/status"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+)
+
+// Chaos result states.
+const (
+	resultStateSOT = "SOT"
+	resultStateEOT = "EOT"
+)
+
+// Kinds of objects events are recorded for.
+const (
+	eventKindResult = "ChaosResult"
+	eventKindEngine = "ChaosEngine"
+)
+
+// Backend records the chaos result and events of an experiment and checks the status of the application under test.
+type Backend interface {
+	// Result creates or updates the chaos result. The state is either SOT (start of test) or EOT (end of test).
+	Result(chaosDetails *types.ChaosDetails, resultDetails *types.ResultDetails, state string) error
+
+	// Event records an event for a ChaosResult or ChaosEngine.
+	Event(chaosDetails *types.ChaosDetails, eventDetails *types.EventDetails, kind string) error
+
+	// ApplicationStatus checks the status of the application under test.
+	ApplicationStatus(chaosDetails *types.ChaosDetails) error
+}
+
+// KubernetesBackend records chaos results and events as Kubernetes resources.
+type KubernetesBackend struct {
+	Clients clients.ClientSets
+}
+
+func (b KubernetesBackend) Result(chaosDetails *types.ChaosDetails, resultDetails *types.ResultDetails, state string) error {
+	if err := result.ChaosResult(chaosDetails, b.Clients, resultDetails, state); err != nil {
+		return err
+	}
+
+	// Events refer to the chaos result by its UID.
+	if state == resultStateSOT {
+		return result.SetResultUID(resultDetails, b.Clients, chaosDetails)
+	}
+
+	return nil
+}
+
+func (b KubernetesBackend) Event(chaosDetails *types.ChaosDetails, eventDetails *types.EventDetails, kind string) error {
+	return events.GenerateEvents(eventDetails, b.Clients, chaosDetails, kind)
+}
+
+func (b KubernetesBackend) ApplicationStatus(chaosDetails *types.ChaosDetails) error {
+	return status.AUTStatusCheck(chaosDetails.AppDetail.Namespace, chaosDetails.AppDetail.Label, "", chaosDetails.Timeout, chaosDetails.Delay, b.Clients, chaosDetails)
+}
+
+// LocalBackend records chaos results and events in a JSON file per chaos result. It does not require a Kubernetes
+// cluster, so experiments can be run from a workstation or CI.
+type LocalBackend struct {
+	Dir string
+
+	mu     sync.Mutex
+	result LocalResult
+}
+
+// LocalResult is the chaos result written by the local backend.
+type LocalResult struct {
+	Name       string                   `json:"name"`
+	Experiment string                   `json:"experiment"`
+	Phase      v1alpha1.ResultPhase     `json:"phase"`
+	Verdict    v1alpha1.ResultVerdict   `json:"verdict"`
+	FailStep   string                   `json:"failStep"`
+	Targets    []v1alpha1.TargetDetails `json:"targets,omitempty"`
+	Events     []LocalEvent             `json:"events,omitempty"`
+}
+
+// LocalEvent is an event recorded by the local backend.
+type LocalEvent struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Reason  string    `json:"reason"`
+	Message string    `json:"message"`
+	Type    string    `json:"type"`
+}
+
+func (b *LocalBackend) Result(chaosDetails *types.ChaosDetails, resultDetails *types.ResultDetails, state string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.result.Name = resultDetails.Name
+	b.result.Experiment = chaosDetails.ExperimentName
+	b.result.Phase = resultDetails.Phase
+	b.result.Verdict = resultDetails.Verdict
+	b.result.FailStep = resultDetails.FailStep
+	b.result.Targets = append([]v1alpha1.TargetDetails(nil), chaosDetails.Targets...)
+	return b.write()
+}
+
+func (b *LocalBackend) Event(chaosDetails *types.ChaosDetails, eventDetails *types.EventDetails, kind string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	log.InfoWithValues("event", map[string]interface{}{
+		"kind":    kind,
+		"message": eventDetails.Message,
+		"reason":  eventDetails.Reason,
+		"type":    eventDetails.Type,
+	})
+
+	b.result.Events = append(b.result.Events, LocalEvent{
+		Time:    time.Now(),
+		Kind:    kind,
+		Reason:  eventDetails.Reason,
+		Message: eventDetails.Message,
+		Type:    eventDetails.Type,
+	})
+	return b.write()
+}
+
+func (b *LocalBackend) ApplicationStatus(chaosDetails *types.ChaosDetails) error {
+	if chaosDetails.AppDetail.Label != "" || chaosDetails.AppDetail.AnnotationCheck {
+		return errors.New("application status checks require a Kubernetes cluster; unset APP_LABEL and ANNOTATION_CHECK")
+	}
+
+	log.Info("[Status]: No Kubernetes cluster, skipping the application status checks")
+	return nil
+}
+
+// Write the result into <dir>/<result name>.json. Must be called with the lock held.
+func (b *LocalBackend) write() error {
+	if b.result.Name == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(b.result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(b.Dir, 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(b.Dir, b.result.Name+".json"), content, 0644)
+}
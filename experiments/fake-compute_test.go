@@ -0,0 +1,537 @@
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+)
+
+// Paths of the Compute Engine API resources served by the fake.
+var (
+	fakeInstancesPath  = regexp.MustCompile(`^/compute/v1/projects/([^/]+)/zones/([^/]+)/instances$`)
+	fakeInstancePath   = regexp.MustCompile(`^/compute/v1/projects/([^/]+)/zones/([^/]+)/instances/([^/]+)(/[a-zA-Z]+)?$`)
+	fakeOperationPath  = regexp.MustCompile(`^/compute/v1/projects/([^/]+)/(?:zones|regions)/([^/]+)/operations/([^/]+)(/wait)?$`)
+	fakeLabelCondition = regexp.MustCompile(`labels\.([^ ]+) = "([^"]*)"`)
+	fakeMIGPath        = regexp.MustCompile(`^/compute/v1/projects/([^/]+)/(?:zones|regions)/([^/]+)/instanceGroupManagers/([^/]+)(/[a-zA-Z]+)?$`)
+	fakeProjectPath    = regexp.MustCompile(`^/resourcemanager/v1/projects/([^/]+):testIamPermissions$`)
+)
+
+// fakeCompute is an in-process Compute Engine API. It models virtual machine instances, their status transitions and
+// zone operations. Operations are done after a configurable number of polls, so waiting can be tested. Managed
+// instance groups are modeled by their target size and instances. Permissions on projects are tested through the
+// Cloud Resource Manager API, which is served as well.
+type fakeCompute struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu         sync.Mutex
+	instances  map[string]*compute.Instance // By zone/name.
+	migs       map[string]*fakeMIG          // By zone/name or region/name.
+	operations map[string]*fakeOperation    // By name.
+	opCount    int
+
+	// Polls (wait or get) until an operation is done.
+	polls int
+
+	// Gets of instances which are booting (e.g. after a reset) until they are running, by zone/name.
+	booting map[string]int
+
+	// Errors returned by the API by method and instance (e.g. "stop zone/name"), and errors reported by operations.
+	errors          map[string]int
+	operationErrors map[string]string
+
+	// IAM permissions granted on all instances and projects.
+	permissions []string
+
+	// Functions called once when an operation is polled by method and instance (e.g. "stop zone/name").
+	hooks map[string]func()
+
+	// Calls of the API by method and instance (e.g. "stop zone/name").
+	calls []string
+}
+
+// fakeOperation is a pending zone operation. The instance transitions to the target status once it is done, or back
+// to its previous status if the operation failed.
+type fakeOperation struct {
+	op       *compute.Operation
+	instance string
+	previous string
+	status   string
+	polls    int
+}
+
+// fakeMIG is a managed instance group. Changes make the group unstable for a number of polls (get).
+type fakeMIG struct {
+	manager   *compute.InstanceGroupManager
+	instances []*compute.ManagedInstance
+	unstable  int
+}
+
+// newFakeCompute starts a fake Compute Engine API. The server is closed when the test finishes.
+func newFakeCompute(t *testing.T) *fakeCompute {
+	f := &fakeCompute{
+		t:               t,
+		instances:       map[string]*compute.Instance{},
+		migs:            map[string]*fakeMIG{},
+		operations:      map[string]*fakeOperation{},
+		polls:           1,
+		booting:         map[string]int{},
+		errors:          map[string]int{},
+		operationErrors: map[string]string{},
+		hooks:           map[string]func(){},
+		permissions: []string{
+			permissionInstanceGroupManagersUpdate,
+			permissionInstancesDelete,
+			permissionInstancesReset,
+			permissionInstancesResume,
+			permissionInstancesSimulateMaintenanceEvent,
+			permissionInstancesStart,
+			permissionInstancesStop,
+			permissionInstancesSuspend,
+		},
+	}
+
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+
+	// Speed up waiting for operations.
+	minBackoff, maxBackoff := operationMinBackoff, operationMaxBackoff
+	operationMinBackoff, operationMaxBackoff = time.Millisecond, time.Millisecond
+	t.Cleanup(func() {
+		operationMinBackoff, operationMaxBackoff = minBackoff, maxBackoff
+	})
+
+	return f
+}
+
+// options configure a Compute Engine API client to use the fake.
+func (f *fakeCompute) options() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithEndpoint(f.server.URL + "/compute/v1/"),
+		option.WithoutAuthentication(),
+	}
+}
+
+// resourceManagerOptions configure a Cloud Resource Manager API client to use the fake.
+func (f *fakeCompute) resourceManagerOptions() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithEndpoint(f.server.URL + "/resourcemanager/"),
+		option.WithoutAuthentication(),
+	}
+}
+
+// service creates a Compute Engine API client using the fake.
+func (f *fakeCompute) service() *compute.Service {
+	svc, err := compute.NewService(context.Background(), f.options()...)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	return svc
+}
+
+// add an instance with the given status and labels.
+func (f *fakeCompute) add(zone, name, status string, labels map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.instances[zone+"/"+name] = &compute.Instance{Name: name, Zone: zone, Status: status, Labels: labels}
+}
+
+// addMIG adds a managed instance group in a zone or region with running instances. The instances of regional groups
+// are placed in zone "<region>-a". The instances can also be accessed as individual instances.
+func (f *fakeCompute) addMIG(location, name string, instances ...string) *fakeMIG {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	zone := location
+	if strings.Count(location, "-") == 1 {
+		zone = location + "-a"
+	}
+
+	mig := &fakeMIG{manager: &compute.InstanceGroupManager{
+		Name:       name,
+		Status:     &compute.InstanceGroupManagerStatus{IsStable: true},
+		TargetSize: int64(len(instances)),
+	}}
+	for _, instance := range instances {
+		mig.instances = append(mig.instances, &compute.ManagedInstance{
+			CurrentAction:  managedInstanceActionNone,
+			Instance:       fmt.Sprintf("%s/compute/v1/projects/project/zones/%s/instances/%s", f.server.URL, zone, instance),
+			InstanceStatus: instanceStatusRunning,
+		})
+		f.instances[zone+"/"+instance] = &compute.Instance{Name: instance, Zone: zone, Status: instanceStatusRunning}
+	}
+
+	f.migs[location+"/"+name] = mig
+	return mig
+}
+
+// migInstances returns the instance names and the target size of a managed instance group.
+func (f *fakeCompute) migInstances(location, name string) ([]string, int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	mig, ok := f.migs[location+"/"+name]
+	if !ok {
+		f.t.Fatalf("unknown group %s/%s", location, name)
+	}
+
+	var names []string
+	for _, instance := range mig.instances {
+		names = append(names, managedInstanceName(instance))
+	}
+
+	return names, mig.manager.TargetSize
+}
+
+// status of an instance.
+func (f *fakeCompute) status(zone, name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	instance, ok := f.instances[zone+"/"+name]
+	if !ok {
+		f.t.Fatalf("unknown instance %s/%s", zone, name)
+	}
+
+	return instance.Status
+}
+
+// called returns the calls of the API with the given method (e.g. "stop").
+func (f *fakeCompute) called(method string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var calls []string
+	for _, call := range f.calls {
+		if strings.HasPrefix(call, method+" ") {
+			calls = append(calls, strings.TrimPrefix(call, method+" "))
+		}
+	}
+
+	return calls
+}
+
+func (f *fakeCompute) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if m := fakeInstancesPath.FindStringSubmatch(r.URL.Path); m != nil && r.Method == http.MethodGet {
+		f.list(w, m[2], r.URL.Query().Get("filter"))
+	} else if m := fakeInstancePath.FindStringSubmatch(r.URL.Path); m != nil {
+		f.instance(w, r, m[2], m[3], strings.TrimPrefix(m[4], "/"))
+	} else if m := fakeMIGPath.FindStringSubmatch(r.URL.Path); m != nil {
+		f.mig(w, r, m[2], m[3], strings.TrimPrefix(m[4], "/"))
+	} else if m := fakeOperationPath.FindStringSubmatch(r.URL.Path); m != nil {
+		f.operation(w, m[3])
+	} else if m := fakeProjectPath.FindStringSubmatch(r.URL.Path); m != nil && r.Method == http.MethodPost {
+		f.project(w, r, m[1])
+	} else {
+		f.error(w, http.StatusNotFound, fmt.Sprintf("unexpected request %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// list the instances in a zone. Only label conditions are supported in filters; other expressions are ignored.
+func (f *fakeCompute) list(w http.ResponseWriter, zone, filter string) {
+	f.calls = append(f.calls, "list "+zone)
+
+	list := &compute.InstanceList{}
+	for _, instance := range f.instances {
+		if instance.Zone != zone {
+			continue
+		}
+
+		matches := true
+		for _, m := range fakeLabelCondition.FindAllStringSubmatch(filter, -1) {
+			if instance.Labels[m[1]] != m[2] {
+				matches = false
+			}
+		}
+
+		if matches {
+			list.Items = append(list.Items, instance)
+		}
+	}
+
+	f.write(w, list)
+}
+
+// instance handles get, reset, resume, simulateMaintenanceEvent, start, stop, suspend and testIamPermissions requests
+// for an instance.
+func (f *fakeCompute) instance(w http.ResponseWriter, r *http.Request, zone, name, method string) {
+	if method == "" {
+		method = "get"
+	}
+
+	key := zone + "/" + name
+	f.calls = append(f.calls, method+" "+key)
+	if code, ok := f.errors[method+" "+key]; ok {
+		f.error(w, code, fmt.Sprintf("injected error for %s %s", method, key))
+		return
+	}
+
+	instance, ok := f.instances[key]
+	if !ok {
+		f.error(w, http.StatusNotFound, fmt.Sprintf("instance %s not found", key))
+		return
+	}
+
+	switch method {
+	case "get":
+		if n, ok := f.booting[key]; ok {
+			if n <= 0 {
+				instance.Status = instanceStatusRunning
+				delete(f.booting, key)
+			} else {
+				f.booting[key] = n - 1
+			}
+		}
+
+		f.write(w, instance)
+	case "reset":
+		// The instance keeps running until the reset is done. It boots afterwards.
+		f.write(w, f.startOperation(key, method, instanceStatusRunning, "STAGING"))
+	case "stop":
+		f.write(w, f.startOperation(key, method, "STOPPING", "TERMINATED"))
+	case "start":
+		f.write(w, f.startOperation(key, method, "STAGING", instanceStatusRunning))
+	case "simulateMaintenanceEvent":
+		// Like instances terminated on host maintenance, the instance boots once the maintenance event is done.
+		f.write(w, f.startOperation(key, method, instanceStatusRunning, "STAGING"))
+	case "suspend":
+		f.write(w, f.startOperation(key, method, "SUSPENDING", "SUSPENDED"))
+	case "resume":
+		f.write(w, f.startOperation(key, method, "STAGING", instanceStatusRunning))
+	case "testIamPermissions":
+		var req compute.TestPermissionsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			f.error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		f.write(w, &compute.TestPermissionsResponse{Permissions: f.granted(req.Permissions)})
+	default:
+		f.error(w, http.StatusNotFound, fmt.Sprintf("unexpected method %s", method))
+	}
+}
+
+// project handles testIamPermissions requests for a project of the Cloud Resource Manager API.
+func (f *fakeCompute) project(w http.ResponseWriter, r *http.Request, project string) {
+	f.calls = append(f.calls, "testProjectIamPermissions "+project)
+
+	var req cloudresourcemanager.TestIamPermissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		f.error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	f.write(w, &cloudresourcemanager.TestIamPermissionsResponse{Permissions: f.granted(req.Permissions)})
+}
+
+// granted returns the given permissions which have been granted.
+func (f *fakeCompute) granted(permissions []string) []string {
+	grants := map[string]bool{}
+	for _, permission := range f.permissions {
+		grants[permission] = true
+	}
+
+	var granted []string
+	for _, permission := range permissions {
+		if grants[permission] {
+			granted = append(granted, permission)
+		}
+	}
+
+	return granted
+}
+
+// mig handles get, listManagedInstances, deleteInstances, recreateInstances and resize requests for a managed instance
+// group.
+// Operations on groups are done immediately, but the group is unstable until it has been polled.
+func (f *fakeCompute) mig(w http.ResponseWriter, r *http.Request, location, name, method string) {
+	if method == "" {
+		method = "get"
+	}
+
+	key := location + "/" + name
+	f.calls = append(f.calls, method+" "+key)
+	if code, ok := f.errors[method+" "+key]; ok {
+		f.error(w, code, fmt.Sprintf("injected error for %s %s", method, key))
+		return
+	}
+
+	mig, ok := f.migs[key]
+	if !ok {
+		f.error(w, http.StatusNotFound, fmt.Sprintf("group %s not found", key))
+		return
+	}
+
+	var req struct {
+		Instances []string `json:"instances"`
+	}
+	if r.Method == http.MethodPost && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			f.error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	switch method {
+	case "get":
+		if mig.unstable > 0 {
+			mig.unstable--
+		}
+
+		mig.manager.Status.IsStable = mig.unstable == 0
+		f.write(w, mig.manager)
+		return
+	case "listManagedInstances":
+		f.write(w, &compute.InstanceGroupManagersListManagedInstancesResponse{ManagedInstances: mig.instances})
+		return
+	case "deleteInstances", "recreateInstances", "resize":
+	default:
+		f.error(w, http.StatusNotFound, fmt.Sprintf("unexpected method %s", method))
+		return
+	}
+
+	f.opCount++
+	op := &compute.Operation{
+		Name:          fmt.Sprintf("operation-%d", f.opCount),
+		OperationType: method,
+		Status:        operationStatusDone,
+		TargetLink:    key,
+	}
+	if msg, ok := f.operationErrors[method+" "+key]; ok {
+		op.Error = &compute.OperationError{Errors: []*compute.OperationErrorErrors{{Code: "INJECTED", Message: msg}}}
+		f.write(w, op)
+		return
+	}
+
+	if method == "deleteInstances" {
+		deleted := map[string]bool{}
+		for _, instance := range req.Instances {
+			deleted[instance] = true
+		}
+
+		var instances []*compute.ManagedInstance
+		for _, instance := range mig.instances {
+			if !deleted[instance.Instance] {
+				instances = append(instances, instance)
+			}
+		}
+
+		mig.manager.TargetSize -= int64(len(mig.instances) - len(instances))
+		mig.instances = instances
+	} else if method == "resize" {
+		size, err := strconv.Atoi(r.URL.Query().Get("size"))
+		if err != nil {
+			f.error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Instances are deleted from the end and created with new names.
+		for len(mig.instances) > size {
+			mig.instances = mig.instances[:len(mig.instances)-1]
+		}
+
+		for len(mig.instances) < size {
+			f.opCount++
+			instance := *mig.instances[0]
+			instance.Instance = fmt.Sprintf("%s/%s-%d", path.Dir(instance.Instance), mig.manager.Name, f.opCount)
+			mig.instances = append(mig.instances, &instance)
+		}
+
+		mig.manager.TargetSize = int64(size)
+	}
+
+	mig.unstable = f.polls
+	mig.manager.Status.IsStable = mig.unstable == 0
+	f.write(w, op)
+}
+
+// startOperation creates a pending operation transitioning the instance to the given status. The instance has the
+// transitional status while the operation is pending.
+func (f *fakeCompute) startOperation(instance, method, transitional, status string) *compute.Operation {
+	f.opCount++
+	op := &compute.Operation{
+		Name:          fmt.Sprintf("operation-%d", f.opCount),
+		OperationType: method,
+		Status:        "RUNNING",
+		TargetLink:    instance,
+	}
+
+	pending := &fakeOperation{op: op, instance: instance, previous: f.instances[instance].Status, status: status}
+	f.instances[instance].Status = transitional
+	if f.polls == 0 {
+		f.finish(pending)
+	} else {
+		f.operations[op.Name] = pending
+	}
+
+	return op
+}
+
+// operation handles get and wait requests for an operation. Each request counts as poll.
+func (f *fakeCompute) operation(w http.ResponseWriter, name string) {
+	pending, ok := f.operations[name]
+	if !ok {
+		f.error(w, http.StatusNotFound, fmt.Sprintf("operation %s not found", name))
+		return
+	}
+
+	if hook, ok := f.hooks[pending.op.OperationType+" "+pending.instance]; ok {
+		delete(f.hooks, pending.op.OperationType+" "+pending.instance)
+		defer hook()
+	}
+
+	pending.polls++
+	if pending.polls >= f.polls && pending.op.Status != operationStatusDone {
+		f.finish(pending)
+	}
+
+	f.write(w, pending.op)
+}
+
+// finish an operation. The instance transitions to the target status, unless an operation error is configured.
+func (f *fakeCompute) finish(pending *fakeOperation) {
+	pending.op.Status = operationStatusDone
+	if msg, ok := f.operationErrors[pending.op.OperationType+" "+pending.instance]; ok {
+		pending.op.Error = &compute.OperationError{Errors: []*compute.OperationErrorErrors{{Code: "INJECTED", Message: msg}}}
+		f.instances[pending.instance].Status = pending.previous
+		return
+	}
+
+	f.instances[pending.instance].Status = pending.status
+	if pending.status == "STAGING" {
+		f.booting[pending.instance] = f.polls
+	}
+}
+
+func (f *fakeCompute) write(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		f.t.Error(err)
+	}
+}
+
+func (f *fakeCompute) error(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{"code": code, "message": msg},
+	})
+}
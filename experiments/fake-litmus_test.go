@@ -0,0 +1,223 @@
+package experiments
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	litmusfake "github.com/litmuschaos/chaos-operator/pkg/client/clientset/versioned/fake"
+	litmus "github.com/litmuschaos/chaos-operator/pkg/client/clientset/versioned/typed/litmuschaos/v1alpha1"
+	clients "github.com/litmuschaos/litmus-go/pkg/clients"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// Path of namespaced resources served by the fake.
+var fakeResourcePath = regexp.MustCompile(`^/(api/v1|apis/litmuschaos\.io/v1alpha1)/namespaces/([^/]+)/([a-z]+)(?:/([^/]+))?$`)
+
+// fakeLitmus serves the Kubernetes and Litmus resources used by experiments from fake clientsets. clients.ClientSets
+// requires concrete clientsets, so the fake clientsets are exposed through an in-process API server. Requests are
+// turned into actions on the fake clientsets, so tests can inspect the objects and the sequence of actions.
+type fakeLitmus struct {
+	t      *testing.T
+	server *httptest.Server
+
+	kube   *kubefake.Clientset
+	litmus *litmusfake.Clientset
+}
+
+// fakeResource maps a resource served by the fake to its clientset.
+type fakeResource struct {
+	fake     *clienttesting.Fake
+	resource schema.GroupVersionResource
+	list     schema.GroupVersionKind
+	new      func() runtime.Object
+}
+
+// newFakeLitmus starts a fake API server seeded with the given Kubernetes (e.g. pods) and Litmus (e.g. ChaosEngine)
+// objects. The server is closed when the test finishes.
+func newFakeLitmus(t *testing.T, objects ...runtime.Object) *fakeLitmus {
+	var kubeObjects, litmusObjects []runtime.Object
+	for _, object := range objects {
+		if object.GetObjectKind().GroupVersionKind().Group == v1alpha1.SchemeGroupVersion.Group {
+			litmusObjects = append(litmusObjects, object)
+		} else {
+			kubeObjects = append(kubeObjects, object)
+		}
+	}
+
+	f := &fakeLitmus{
+		t:      t,
+		kube:   kubefake.NewSimpleClientset(kubeObjects...),
+		litmus: litmusfake.NewSimpleClientset(litmusObjects...),
+	}
+
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+// clients using the fake API server.
+func (f *fakeLitmus) clients() clients.ClientSets {
+	config := &rest.Config{Host: f.server.URL}
+
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	litmusClient, err := litmus.NewForConfig(config)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	return clients.ClientSets{KubeClient: kubeClient, LitmusClient: litmusClient, KubeConfig: config}
+}
+
+// events returns the events created or updated so far as "<kind> <reason>: <message>".
+func (f *fakeLitmus) events() []string {
+	var events []string
+	for _, action := range f.kube.Actions() {
+		if object, ok := written(action).(*corev1.Event); ok {
+			events = append(events, fmt.Sprintf("%s %s: %s", object.InvolvedObject.Kind, object.Reason, object.Message))
+		}
+	}
+
+	return events
+}
+
+// results returns the chaos results created or updated so far as "<phase>/<verdict>".
+func (f *fakeLitmus) results() []string {
+	var results []string
+	for _, action := range f.litmus.Actions() {
+		if object, ok := written(action).(*v1alpha1.ChaosResult); ok {
+			results = append(results, fmt.Sprintf("%s/%s", object.Status.ExperimentStatus.Phase, object.Status.ExperimentStatus.Verdict))
+		}
+	}
+
+	return results
+}
+
+// result returns a chaos result.
+func (f *fakeLitmus) result(namespace, name string) *v1alpha1.ChaosResult {
+	result, err := f.litmus.LitmuschaosV1alpha1().ChaosResults(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	return result
+}
+
+// written returns the object of create and update actions.
+func written(action clienttesting.Action) runtime.Object {
+	switch action := action.(type) {
+	case clienttesting.CreateAction:
+		return action.GetObject()
+	case clienttesting.UpdateAction:
+		return action.GetObject()
+	default:
+		return nil
+	}
+}
+
+func (f *fakeLitmus) resource(name string) (fakeResource, bool) {
+	switch name {
+	case "events":
+		return fakeResource{&f.kube.Fake, corev1.SchemeGroupVersion.WithResource(name), corev1.SchemeGroupVersion.WithKind("EventList"), func() runtime.Object { return &corev1.Event{} }}, true
+	case "pods":
+		return fakeResource{&f.kube.Fake, corev1.SchemeGroupVersion.WithResource(name), corev1.SchemeGroupVersion.WithKind("PodList"), func() runtime.Object { return &corev1.Pod{} }}, true
+	case "secrets":
+		return fakeResource{&f.kube.Fake, corev1.SchemeGroupVersion.WithResource(name), corev1.SchemeGroupVersion.WithKind("SecretList"), func() runtime.Object { return &corev1.Secret{} }}, true
+	case "chaosengines":
+		return fakeResource{&f.litmus.Fake, v1alpha1.SchemeGroupVersion.WithResource(name), v1alpha1.SchemeGroupVersion.WithKind("ChaosEngineList"), func() runtime.Object { return &v1alpha1.ChaosEngine{} }}, true
+	case "chaosexperiments":
+		return fakeResource{&f.litmus.Fake, v1alpha1.SchemeGroupVersion.WithResource(name), v1alpha1.SchemeGroupVersion.WithKind("ChaosExperimentList"), func() runtime.Object { return &v1alpha1.ChaosExperiment{} }}, true
+	case "chaosresults":
+		return fakeResource{&f.litmus.Fake, v1alpha1.SchemeGroupVersion.WithResource(name), v1alpha1.SchemeGroupVersion.WithKind("ChaosResultList"), func() runtime.Object { return &v1alpha1.ChaosResult{} }}, true
+	default:
+		return fakeResource{}, false
+	}
+}
+
+func (f *fakeLitmus) serve(w http.ResponseWriter, r *http.Request) {
+	m := fakeResourcePath.FindStringSubmatch(r.URL.Path)
+	if m == nil {
+		f.error(w, apierrors.NewNotFound(schema.GroupResource{}, r.URL.Path))
+		return
+	}
+
+	namespace, name := m[2], m[4]
+	res, ok := f.resource(m[3])
+	if !ok {
+		f.error(w, apierrors.NewNotFound(schema.GroupResource{Resource: m[3]}, name))
+		return
+	}
+
+	var action clienttesting.Action
+	switch {
+	case r.Method == http.MethodGet && name != "":
+		action = clienttesting.NewGetAction(res.resource, namespace, name)
+	case r.Method == http.MethodGet:
+		selector, err := metav1.ParseToLabelSelector(r.URL.Query().Get("labelSelector"))
+		if err != nil {
+			f.error(w, apierrors.NewBadRequest(err.Error()))
+			return
+		}
+
+		opts := metav1.ListOptions{LabelSelector: metav1.FormatLabelSelector(selector)}
+		action = clienttesting.NewListAction(res.resource, res.list, namespace, opts)
+	case r.Method == http.MethodPost || r.Method == http.MethodPut:
+		object := res.new()
+		if err := json.NewDecoder(r.Body).Decode(object); err != nil {
+			f.error(w, apierrors.NewBadRequest(err.Error()))
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			action = clienttesting.NewCreateAction(res.resource, namespace, object)
+		} else {
+			action = clienttesting.NewUpdateAction(res.resource, namespace, object)
+		}
+	default:
+		f.error(w, apierrors.NewMethodNotSupported(res.resource.GroupResource(), r.Method))
+		return
+	}
+
+	object, err := res.fake.Invokes(action, nil)
+	if err != nil {
+		f.error(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(object); err != nil {
+		f.t.Error(err)
+	}
+}
+
+// error writes the status of an API error. Other errors are reported as internal errors.
+func (f *fakeLitmus) error(w http.ResponseWriter, err error) {
+	status, ok := err.(apierrors.APIStatus)
+	if !ok {
+		status = apierrors.NewInternalError(err)
+	}
+
+	s := status.Status()
+	s.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Status"}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(s.Code))
+	_ = json.NewEncoder(w).Encode(s)
+}
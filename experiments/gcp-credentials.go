@@ -0,0 +1,93 @@
+package experiments
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Reference to a key of a Kubernetes secret (name:key or namespace/name:key).
+var secretKeyRef = regexp.MustCompile(`^(?:([^/:]+)/)?([^/:]+):([^/:]+)$`)
+
+// E-mail address of a Google service account (e.g. name@project.iam.gserviceaccount.com).
+var serviceAccountEmail = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.gserviceaccount\.com$`)
+
+// Additional client options used to impersonate service accounts through the IAM Credentials API. This is a variable,
+// so it can be adjusted in tests.
+var impersonateOptions []option.ClientOption
+
+// GCPCredentialDetails configure the credentials used for the Google Cloud APIs. By default, Application Default
+// Credentials are used, e.g. through GKE Workload Identity. It can be embedded by the specific experiment details.
+type GCPCredentialDetails struct {
+	GCPCredentialsSecret         string `desc:"Read the credentials JSON from this Kubernetes secret key (name:key) in the chaos namespace instead of using the default credentials" split_words:"true"`
+	GCPImpersonateServiceAccount string `desc:"Impersonate this Google service account (e-mail address) instead of using the default credentials directly" split_words:"true"`
+}
+
+// clientOptions authenticate Google Cloud API clients. Credentials read from a Kubernetes secret in the chaos namespace
+// replace the default credentials. If a service account is impersonated, these credentials are used to obtain
+// short-lived tokens for the service account.
+func (d GCPCredentialDetails) clientOptions(ctx context.Context, kubeClient kubernetes.Interface, chaosNamespace string) ([]option.ClientOption, error) {
+	var opts []option.ClientOption
+	if d.GCPCredentialsSecret != "" {
+		credentials, err := d.secretCredentials(kubeClient, chaosNamespace)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, option.WithCredentialsJSON(credentials))
+	}
+
+	if d.GCPImpersonateServiceAccount == "" {
+		return opts, nil
+	} else if !serviceAccountEmail.MatchString(d.GCPImpersonateServiceAccount) {
+		return nil, fmt.Errorf("invalid GCP_IMPERSONATE_SERVICE_ACCOUNT %q, expected the e-mail address of a service account", d.GCPImpersonateServiceAccount)
+	}
+
+	ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: d.GCPImpersonateServiceAccount,
+		Scopes:          []string{compute.CloudPlatformScope},
+	}, append(opts, impersonateOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return []option.ClientOption{option.WithTokenSource(ts)}, nil
+}
+
+// secretCredentials reads the credentials JSON from the configured Kubernetes secret key. The experiment is only
+// allowed to read secrets in the chaos namespace (see rbac.yaml), so other namespaces are rejected.
+func (d GCPCredentialDetails) secretCredentials(kubeClient kubernetes.Interface, chaosNamespace string) ([]byte, error) {
+	m := secretKeyRef.FindStringSubmatch(d.GCPCredentialsSecret)
+	if m == nil {
+		return nil, fmt.Errorf("invalid GCP_CREDENTIALS_SECRET %q, expected name:key", d.GCPCredentialsSecret)
+	}
+
+	namespace, name, key := m[1], m[2], m[3]
+	if namespace == "" {
+		namespace = chaosNamespace
+	} else if namespace != chaosNamespace {
+		return nil, fmt.Errorf("GCP_CREDENTIALS_SECRET %q must be in the chaos namespace %s", d.GCPCredentialsSecret, chaosNamespace)
+	}
+
+	if kubeClient == nil {
+		return nil, fmt.Errorf("reading credentials from a secret (GCP_CREDENTIALS_SECRET) requires a Kubernetes cluster")
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials secret %s/%s: %w", namespace, name, err)
+	}
+
+	credentials, ok := secret.Data[key]
+	if !ok || len(credentials) == 0 {
+		return nil, fmt.Errorf("credentials secret %s/%s has no key %q", namespace, name, key)
+	}
+
+	return credentials, nil
+}
@@ -0,0 +1,158 @@
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeIAMCredentials serves the IAM Credentials API, issuing a token for each of the given service accounts, and a
+// Compute Engine API recording the tokens used. The endpoint of the IAM Credentials API cannot be configured, so the
+// requests made to impersonate service accounts are redirected to the fake.
+type fakeIAMCredentials struct {
+	server *httptest.Server
+
+	mu     sync.Mutex
+	tokens []string
+}
+
+// newFakeIAMCredentials starts the fake and redirects impersonation to it. The server is closed when the test finishes.
+func newFakeIAMCredentials(t *testing.T, serviceAccounts ...string) *fakeIAMCredentials {
+	f := &fakeIAMCredentials{}
+
+	mux := http.NewServeMux()
+	for _, sa := range serviceAccounts {
+		token := "token-" + sa
+		mux.HandleFunc("/v1/projects/-/serviceAccounts/"+sa+":generateAccessToken", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"accessToken": token,
+				"expireTime":  time.Now().Add(time.Hour).Format(time.RFC3339),
+			})
+		})
+	}
+
+	mux.HandleFunc("/compute/v1/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.tokens = append(f.tokens, r.Header.Get("Authorization"))
+		f.mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(&compute.Instance{Name: "vm-1", Status: instanceStatusRunning})
+	})
+
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+
+	target, err := url.Parse(f.server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := impersonateOptions
+	impersonateOptions = []option.ClientOption{option.WithHTTPClient(&http.Client{Transport: redirect{target}})}
+	t.Cleanup(func() {
+		impersonateOptions = options
+	})
+
+	return f
+}
+
+// redirect sends all requests to the target host.
+type redirect struct {
+	target *url.URL
+}
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host, req.Host = r.target.Scheme, r.target.Host, r.target.Host
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// getInstance gets an instance from the fake Compute Engine API using the given client options.
+func (f *fakeIAMCredentials) getInstance(opts []option.ClientOption) error {
+	svc, err := compute.NewService(context.Background(), append(opts, option.WithEndpoint(f.server.URL+"/compute/v1/"))...)
+	if err != nil {
+		return err
+	}
+
+	_, err = svc.Instances.Get("project", "zone-a", "vm-1").Do()
+	return err
+}
+
+func TestClientOptionsDefaultCredentials(t *testing.T) {
+	opts, err := GCPCredentialDetails{}.clientOptions(context.Background(), nil, "litmus")
+	assert.NoError(t, err)
+	assert.Empty(t, opts)
+}
+
+func TestClientOptionsSecret(t *testing.T) {
+	fake := newFakeLitmus(t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "litmus", Name: "gcp"},
+		Data:       map[string][]byte{"key.json": []byte(`{"type": "service_account"}`)},
+	})
+	kubeClient := fake.clients().KubeClient
+
+	for _, secret := range []string{"gcp:key.json", "litmus/gcp:key.json"} {
+		opts, err := GCPCredentialDetails{GCPCredentialsSecret: secret}.clientOptions(context.Background(), kubeClient, "litmus")
+		assert.NoError(t, err, secret)
+		assert.Len(t, opts, 1, secret)
+	}
+
+	for secret, msg := range map[string]string{
+		"key.json":              `invalid GCP_CREDENTIALS_SECRET "key.json", expected name:key`,
+		"default/gcp:key.json":  `GCP_CREDENTIALS_SECRET "default/gcp:key.json" must be in the chaos namespace litmus`,
+		"litmus/gcp:other.json": `credentials secret litmus/gcp has no key "other.json"`,
+		"litmus/other:key.json": `failed to read credentials secret litmus/other: secrets "other" not found`,
+	} {
+		_, err := GCPCredentialDetails{GCPCredentialsSecret: secret}.clientOptions(context.Background(), kubeClient, "litmus")
+		assert.EqualError(t, err, msg, secret)
+	}
+}
+
+func TestClientOptionsSecretWithoutCluster(t *testing.T) {
+	_, err := GCPCredentialDetails{GCPCredentialsSecret: "gcp:key.json"}.clientOptions(context.Background(), nil, "litmus")
+	assert.EqualError(t, err, "reading credentials from a secret (GCP_CREDENTIALS_SECRET) requires a Kubernetes cluster")
+}
+
+func TestClientOptionsImpersonate(t *testing.T) {
+	fake := newFakeIAMCredentials(t, "chaos@project.iam.gserviceaccount.com")
+
+	d := GCPCredentialDetails{GCPImpersonateServiceAccount: "chaos@project.iam.gserviceaccount.com"}
+	opts, err := d.clientOptions(context.Background(), nil, "litmus")
+	assert.NoError(t, err)
+
+	// The token issued for the impersonated service account is used.
+	assert.NoError(t, fake.getInstance(opts))
+	assert.Equal(t, []string{"Bearer token-chaos@project.iam.gserviceaccount.com"}, fake.tokens)
+}
+
+func TestClientOptionsImpersonateUnknown(t *testing.T) {
+	fake := newFakeIAMCredentials(t, "chaos@project.iam.gserviceaccount.com")
+
+	// Tokens are requested once the API is called.
+	d := GCPCredentialDetails{GCPImpersonateServiceAccount: "other@project.iam.gserviceaccount.com"}
+	opts, err := d.clientOptions(context.Background(), nil, "litmus")
+	assert.NoError(t, err)
+
+	err = fake.getInstance(opts)
+	assert.ErrorContains(t, err, "impersonate: status code 404")
+	assert.Empty(t, fake.tokens)
+}
+
+func TestClientOptionsImpersonateInvalid(t *testing.T) {
+	for _, sa := range []string{"chaos", "chaos@project", "chaos@example.com", "chaos @project.iam.gserviceaccount.com"} {
+		_, err := GCPCredentialDetails{GCPImpersonateServiceAccount: sa}.clientOptions(context.Background(), nil, "litmus")
+		assert.EqualError(t, err, `invalid GCP_IMPERSONATE_SERVICE_ACCOUNT "`+sa+`", expected the e-mail address of a service account`)
+	}
+}
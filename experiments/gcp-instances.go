@@ -0,0 +1,353 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"google.golang.org/api/compute/v1"
+)
+
+// Instance status reported by the Compute Engine API for running instances.
+const instanceStatusRunning = "RUNNING"
+
+// Kind of virtual machine instance targets recorded in the chaos result.
+const targetKindInstance = "VM"
+
+// Sequences in which multiple instances are targeted.
+const (
+	sequenceParallel = "parallel"
+	sequenceSerial   = "serial"
+)
+
+// GCPInstanceDetails select the virtual machine instances targeted by an experiment. Instances are either selected by
+// name or by labels and filter expression. It can be embedded by the specific experiment details.
+type GCPInstanceDetails struct {
+	GCPInstance          []string `desc:"The virtual machine instances (comma separated)" split_words:"true"`
+	GCPInstanceFilter    string   `desc:"Select running instances by filter expression" split_words:"true"`
+	GCPInstanceLabels    string   `desc:"Select running instances by labels (comma separated key=value pairs)" split_words:"true"`
+	GCPProject           string   `desc:"The virtual machine instances project" required:"true" split_words:"true"`
+	GCPZone              []string `desc:"The virtual machine instances zones (comma separated, a single zone or one per instance)" required:"true" split_words:"true"`
+	InstanceAffectedPerc int      `default:"100" desc:"The percentage of instances selected by labels or filter to target" split_words:"true"`
+	Sequence             string   `default:"parallel" desc:"Target the instances one at a time (serial) or all at once (parallel)"`
+}
+
+// gcpInstance identifies a virtual machine instance within a project.
+type gcpInstance struct {
+	zone string
+	name string
+}
+
+func (i gcpInstance) String() string {
+	return i.zone + "/" + i.name
+}
+
+// filter builds a Compute Engine filter expression from the configured labels and filter expression. Labels are
+// given as comma separated list of key=value pairs.
+func (d GCPInstanceDetails) filter() (string, error) {
+	var exprs []string
+	if d.GCPInstanceLabels != "" {
+		for _, label := range strings.Split(d.GCPInstanceLabels, ",") {
+			kv := strings.SplitN(label, "=", 2)
+			if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+				return "", fmt.Errorf("invalid label %q in GCP_INSTANCE_LABELS; expected key=value", label)
+			}
+
+			exprs = append(exprs, fmt.Sprintf("(labels.%s = %q)", strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])))
+		}
+	}
+
+	if d.GCPInstanceFilter != "" {
+		exprs = append(exprs, fmt.Sprintf("(%s)", d.GCPInstanceFilter))
+	}
+
+	return strings.Join(exprs, " "), nil
+}
+
+// targets resolves the targeted instances. Instances selected by name are paired with the zone at the same position,
+// or with the only zone if a single zone is given; all of them are targeted. If the instances are selected by labels or
+// filter, running instances in all zones are considered and a random subset is picked according to the affected
+// percentage.
+func (d GCPInstanceDetails) targets(ctx context.Context, svc *compute.Service) ([]gcpInstance, error) {
+	if d.Sequence != sequenceParallel && d.Sequence != sequenceSerial {
+		return nil, fmt.Errorf("SEQUENCE must be %q or %q, got %q", sequenceParallel, sequenceSerial, d.Sequence)
+	}
+
+	filter, err := d.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.InstanceAffectedPerc <= 0 || d.InstanceAffectedPerc > 100 {
+		return nil, fmt.Errorf("INSTANCE_AFFECTED_PERC must be between 1 and 100, got %d", d.InstanceAffectedPerc)
+	}
+
+	if len(d.GCPInstance) != 0 && filter != "" {
+		return nil, errors.New("GCP_INSTANCE must not be combined with GCP_INSTANCE_LABELS or GCP_INSTANCE_FILTER")
+	} else if len(d.GCPInstance) != 0 && d.InstanceAffectedPerc != 100 {
+		return nil, fmt.Errorf("INSTANCE_AFFECTED_PERC must not be combined with GCP_INSTANCE, got %d", d.InstanceAffectedPerc)
+	} else if len(d.GCPInstance) != 0 {
+		return d.instancesByName()
+	} else if filter == "" {
+		return nil, errors.New("one of GCP_INSTANCE, GCP_INSTANCE_LABELS or GCP_INSTANCE_FILTER is required")
+	}
+
+	var instances []gcpInstance
+	for _, zone := range d.GCPZone {
+		running, err := runningInstances(ctx, svc, d.GCPProject, strings.TrimSpace(zone), filter)
+		if err != nil {
+			return nil, err
+		}
+
+		instances = append(instances, running...)
+	}
+
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("no running instances in zones %s match filter %q", strings.Join(d.GCPZone, ", "), filter)
+	}
+
+	// Pick a random subset, but at least one instance.
+	n := len(instances) * d.InstanceAffectedPerc / 100
+	if n == 0 {
+		n = 1
+	}
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	rnd.Shuffle(len(instances), func(i, j int) {
+		instances[i], instances[j] = instances[j], instances[i]
+	})
+
+	return instances[:n], nil
+}
+
+// runningInstances lists the running instances in a zone matching the filter expression. All running instances are
+// listed, if the filter is empty.
+func runningInstances(ctx context.Context, svc *compute.Service, project, zone, filter string) ([]gcpInstance, error) {
+	var instances []gcpInstance
+	err := svc.Instances.List(project, zone).Filter(filter).Pages(ctx, func(list *compute.InstanceList) error {
+		for _, instance := range list.Items {
+			if instance.Status == instanceStatusRunning {
+				instances = append(instances, gcpInstance{zone: zone, name: instance.Name})
+			}
+		}
+
+		return nil
+	})
+
+	return instances, err
+}
+
+// instancesByName pairs the configured instance names with their zones.
+func (d GCPInstanceDetails) instancesByName() ([]gcpInstance, error) {
+	if len(d.GCPZone) != 1 && len(d.GCPZone) != len(d.GCPInstance) {
+		return nil, fmt.Errorf("GCP_ZONE must contain a single zone or one zone per instance, got %d zones for %d instances", len(d.GCPZone), len(d.GCPInstance))
+	}
+
+	instances := make([]gcpInstance, len(d.GCPInstance))
+	for i, name := range d.GCPInstance {
+		zone := d.GCPZone[0]
+		if len(d.GCPZone) != 1 {
+			zone = d.GCPZone[i]
+		}
+
+		instances[i] = gcpInstance{zone: strings.TrimSpace(zone), name: strings.TrimSpace(name)}
+	}
+
+	return instances, nil
+}
+
+// selectInstances resolves the targeted instances, logs them and records them in the chaos result.
+func (e *Experiment) selectInstances(ctx context.Context, svc *compute.Service, d GCPInstanceDetails) ([]gcpInstance, error) {
+	instances, err := d.targets(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(instances))
+	for i, instance := range instances {
+		names[i] = instance.String()
+		e.SetTarget(instance.String(), targetKindInstance, targetStatusTargeted)
+	}
+
+	log.InfoWithValues("targeting instances", map[string]interface{}{
+		"experiment": e.ChaosDetails.ExperimentName,
+		"instances":  strings.Join(names, ", "),
+		"project":    d.GCPProject,
+		"sequence":   d.Sequence,
+	})
+
+	return instances, nil
+}
+
+// forEachInstance calls f for each instance, either one at a time or all at once. Errors are collected, so a failure
+// for one instance does not prevent f from being called for the others.
+func forEachInstance(ctx context.Context, sequence string, instances []gcpInstance, f func(context.Context, gcpInstance) error) error {
+	limit := len(instances)
+	if sequence == sequenceSerial {
+		limit = 1
+	}
+
+	return forEachInstanceLimit(ctx, limit, instances, f)
+}
+
+// forEachInstanceLimit calls f for each instance, with at most limit calls at the same time. Instances are processed
+// in order. Errors are collected, so a failure for one instance does not prevent f from being called for the others.
+func forEachInstanceLimit(ctx context.Context, limit int, instances []gcpInstance, f func(context.Context, gcpInstance) error) error {
+	if limit < 1 {
+		limit = 1
+	}
+
+	var mu sync.Mutex
+	var msgs []string
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for _, instance := range instances {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(instance gcpInstance) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := f(ctx, instance); err != nil {
+				log.ErrorWithValues(fmt.Sprintf("instance failed: %v", err), map[string]interface{}{
+					"instance": instance.String(),
+				})
+
+				mu.Lock()
+				msgs = append(msgs, fmt.Sprintf("instance %s: %v", instance, err))
+				mu.Unlock()
+			}
+		}(instance)
+	}
+	wg.Wait()
+
+	if len(msgs) != 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+
+	return nil
+}
+
+// dryRunInstances resolves the targeted instances and checks that each of them is running and that the given IAM
+// permissions have been granted. The instances are not changed; instead, the action which would have been taken is
+// reported in the chaos result.
+func (e *Experiment) dryRunInstances(ctx context.Context, svc *compute.Service, d GCPInstanceDetails, action string, permissions ...string) error {
+	instances, err := e.selectInstances(ctx, svc, d)
+	if err != nil {
+		return err
+	}
+
+	err = forEachInstance(ctx, d.Sequence, instances, func(ctx context.Context, instance gcpInstance) error {
+		return checkInstance(ctx, svc, d.GCPProject, instance.zone, instance.name, permissions)
+	})
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(instances))
+	for i, instance := range instances {
+		names[i] = instance.String()
+	}
+
+	log.InfoWithValues(fmt.Sprintf("[Dry-run]: would %s instances", action), map[string]interface{}{
+		"experiment":  e.ChaosDetails.ExperimentName,
+		"instances":   strings.Join(names, ", "),
+		"permissions": strings.Join(permissions, ", "),
+		"project":     d.GCPProject,
+	})
+	e.updateResult(reasonDryRun, fmt.Sprintf("dry-run: would %s instances %s", action, strings.Join(names, ", ")), eventTypeNormal)
+
+	return nil
+}
+
+// instanceAction changes a virtual machine instance and waits for the operation to finish.
+type instanceAction func(ctx context.Context, svc *compute.Service, project, zone, instance string) error
+
+// stopInstances stops the targeted instances. Starting each stopped instance is registered as revert, so instances
+// are started again if the experiment is aborted or fails.
+func (e *Experiment) stopInstances(ctx context.Context, svc *compute.Service, d GCPInstanceDetails) error {
+	return e.injectInstances(ctx, svc, d, requestStop, "start", requestStart)
+}
+
+// suspendInstances suspends the targeted instances. Resuming each suspended instance is registered as revert, so
+// instances are resumed if the experiment is aborted or fails.
+func (e *Experiment) suspendInstances(ctx context.Context, svc *compute.Service, d GCPInstanceDetails) error {
+	return e.injectInstances(ctx, svc, d, requestSuspend, "resume", requestResume)
+}
+
+// injectInstances applies the action to the targeted instances. The named revert action is registered for each
+// instance as soon as the action has been requested, so the instance is also reverted if waiting for the action is
+// interrupted (e.g. by an abort).
+func (e *Experiment) injectInstances(ctx context.Context, svc *compute.Service, d GCPInstanceDetails, action instanceRequest, name string, revert instanceRequest) error {
+	instances, err := e.selectInstances(ctx, svc, d)
+	if err != nil {
+		return err
+	}
+
+	return forEachInstance(ctx, d.Sequence, instances, func(ctx context.Context, instance gcpInstance) error {
+		op, err := action(ctx, svc, d.GCPProject, instance.zone, instance.name)
+		if err != nil {
+			return err
+		}
+
+		e.AddRevert(name+" instance "+instance.String(), func(ctx context.Context) error {
+			// The action might still be in progress. There is nothing to revert, if it failed.
+			var failed *operationFailedError
+			if err := waitForZoneOperation(ctx, svc, d.GCPProject, instance.zone, op); errors.As(err, &failed) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if err := changeInstance(ctx, svc, d.GCPProject, instance.zone, instance.name, revert); err != nil {
+				return err
+			}
+
+			e.SetTarget(instance.String(), targetKindInstance, targetStatusReverted)
+			return nil
+		})
+
+		if err := waitForZoneOperation(ctx, svc, d.GCPProject, instance.zone, op); err != nil {
+			return err
+		}
+
+		e.SetTarget(instance.String(), targetKindInstance, targetStatusInjected)
+		return nil
+	})
+}
+
+// resetInstances resets the targeted instances. Resets cannot be reverted; each instance is running again once it has
+// been reset.
+func (e *Experiment) resetInstances(ctx context.Context, svc *compute.Service, d GCPInstanceDetails) error {
+	return e.disruptInstances(ctx, svc, d, resetInstance)
+}
+
+// maintainInstances simulates host maintenance events for the targeted instances. Maintenance events cannot be
+// reverted; each instance is running again once the maintenance event has finished.
+func (e *Experiment) maintainInstances(ctx context.Context, svc *compute.Service, d GCPInstanceDetails) error {
+	return e.disruptInstances(ctx, svc, d, simulateMaintenanceEvent)
+}
+
+// disruptInstances applies an action to the targeted instances, which the instances recover from without a revert.
+func (e *Experiment) disruptInstances(ctx context.Context, svc *compute.Service, d GCPInstanceDetails, action instanceAction) error {
+	instances, err := e.selectInstances(ctx, svc, d)
+	if err != nil {
+		return err
+	}
+
+	return forEachInstance(ctx, d.Sequence, instances, func(ctx context.Context, instance gcpInstance) error {
+		if err := action(ctx, svc, d.GCPProject, instance.zone, instance.name); err != nil {
+			return err
+		}
+
+		e.SetTarget(instance.String(), targetKindInstance, targetStatusInjected)
+		return nil
+	})
+}
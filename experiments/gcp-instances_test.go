@@ -0,0 +1,120 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTargetsByName(t *testing.T) {
+	d := GCPInstanceDetails{
+		GCPInstance:          []string{"vm-1", " vm-2"},
+		GCPProject:           "project",
+		GCPZone:              []string{"zone-a"},
+		InstanceAffectedPerc: 100,
+		Sequence:             sequenceParallel,
+	}
+
+	instances, err := d.targets(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []gcpInstance{{zone: "zone-a", name: "vm-1"}, {zone: "zone-a", name: "vm-2"}}, instances)
+
+	d.GCPZone = []string{"zone-a", "zone-b", "zone-c"}
+	_, err = d.targets(context.Background(), nil)
+	assert.EqualError(t, err, "GCP_ZONE must contain a single zone or one zone per instance, got 3 zones for 2 instances")
+
+	// All instances selected by name are targeted.
+	d.GCPZone = []string{"zone-a"}
+	d.InstanceAffectedPerc = 50
+	_, err = d.targets(context.Background(), nil)
+	assert.EqualError(t, err, "INSTANCE_AFFECTED_PERC must not be combined with GCP_INSTANCE, got 50")
+
+	d.InstanceAffectedPerc = 0
+	_, err = d.targets(context.Background(), nil)
+	assert.EqualError(t, err, "INSTANCE_AFFECTED_PERC must be between 1 and 100, got 0")
+}
+
+func TestTargetsByLabels(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, map[string]string{"env": "test"})
+	fake.add("zone-a", "vm-2", "TERMINATED", map[string]string{"env": "test"})
+	fake.add("zone-b", "vm-3", instanceStatusRunning, map[string]string{"env": "test"})
+	fake.add("zone-b", "vm-4", instanceStatusRunning, map[string]string{"env": "prod"})
+
+	d := GCPInstanceDetails{
+		GCPInstanceLabels:    "env=test",
+		GCPProject:           "project",
+		GCPZone:              []string{"zone-a", "zone-b"},
+		InstanceAffectedPerc: 100,
+		Sequence:             sequenceParallel,
+	}
+
+	instances, err := d.targets(context.Background(), fake.service())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []gcpInstance{{zone: "zone-a", name: "vm-1"}, {zone: "zone-b", name: "vm-3"}}, instances)
+
+	// At least one instance is targeted.
+	d.InstanceAffectedPerc = 1
+	instances, err = d.targets(context.Background(), fake.service())
+	assert.NoError(t, err)
+	assert.Len(t, instances, 1)
+
+	d.GCPInstanceLabels = "env=none"
+	_, err = d.targets(context.Background(), fake.service())
+	assert.EqualError(t, err, `no running instances in zones zone-a, zone-b match filter "(labels.env = \"none\")"`)
+}
+
+func TestTargetsInvalid(t *testing.T) {
+	for name, d := range map[string]GCPInstanceDetails{
+		"sequence":   {GCPInstance: []string{"vm-1"}, GCPZone: []string{"zone-a"}, InstanceAffectedPerc: 100, Sequence: "random"},
+		"none":       {GCPZone: []string{"zone-a"}, InstanceAffectedPerc: 100, Sequence: sequenceParallel},
+		"both":       {GCPInstance: []string{"vm-1"}, GCPInstanceFilter: "name = vm-1", GCPZone: []string{"zone-a"}, InstanceAffectedPerc: 100, Sequence: sequenceParallel},
+		"label":      {GCPInstanceLabels: "env", GCPZone: []string{"zone-a"}, InstanceAffectedPerc: 100, Sequence: sequenceParallel},
+		"percentage": {GCPInstanceFilter: "name = vm-1", GCPZone: []string{"zone-a"}, InstanceAffectedPerc: 101, Sequence: sequenceParallel},
+		"names":      {GCPInstance: []string{"vm-1"}, GCPZone: []string{"zone-a"}, InstanceAffectedPerc: 101, Sequence: sequenceParallel},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := d.targets(context.Background(), nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestForEachInstanceLimit(t *testing.T) {
+	var instances []gcpInstance
+	for i := 0; i < 10; i++ {
+		instances = append(instances, gcpInstance{zone: "zone-a", name: fmt.Sprintf("vm-%d", i)})
+	}
+
+	var mu sync.Mutex
+	var running, max int32
+	var called []string
+	err := forEachInstanceLimit(context.Background(), 3, instances, func(ctx context.Context, instance gcpInstance) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		mu.Lock()
+		called = append(called, instance.name)
+		if n > max {
+			max = n
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+		if instance.name == "vm-4" {
+			return errors.New("boom")
+		}
+
+		return nil
+	})
+	assert.EqualError(t, err, "instance zone-a/vm-4: boom")
+
+	assert.Len(t, called, 10)
+	assert.LessOrEqual(t, max, int32(3))
+}
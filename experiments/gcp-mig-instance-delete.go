@@ -0,0 +1,168 @@
+package experiments
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jaconi-io/litmus/environment"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"google.golang.org/api/compute/v1"
+)
+
+// Actions taken on the targeted instances of a managed instance group.
+const (
+	migActionDelete   = "delete"
+	migActionRecreate = "recreate"
+)
+
+func init() {
+	Experiments.Register(Definition{
+		Name:        "gcp-mig-instance-delete",
+		Description: "Delete or recreate instances of a managed instance group",
+		Details:     &gcpMIGInstanceDeleteDetails{},
+		Defaults:    map[string]string{"TOTAL_CHAOS_DURATION": "0"},
+		Run:         GCPMIGInstanceDelete,
+	})
+}
+
+// gcpMIGInstanceDeleteDetails extend the default experiment details.
+type gcpMIGInstanceDeleteDetails struct {
+	environment.ExperimentDetails
+	GCPMIGDetails
+	GCPCredentialDetails
+	InstanceCount   int    `default:"1" desc:"The number of instances to target" split_words:"true"`
+	MIGAction       string `default:"recreate" desc:"Recreate the instances (recreate) or delete them, reducing the target size of the group (delete)" envconfig:"MIG_ACTION"`
+	MIGAffectedPerc int    `default:"0" desc:"The percentage of instances to target (takes precedence over INSTANCE_COUNT)" envconfig:"MIG_AFFECTED_PERC"`
+}
+
+// GCPMIGInstanceDelete deletes or recreates instances of a managed instance group and waits for the group to become
+// stable again. Deleted instances reduce the target size of the group, so they are only replaced by an autoscaler.
+// If a chaos interval is configured, instances are deleted every interval, until the chaos duration has elapsed.
+func GCPMIGInstanceDelete(opts Options) error {
+	details := &gcpMIGInstanceDeleteDetails{}
+	experiment, err := NewExperiment("gcp-mig-instance-delete", opts, details)
+	if err != nil {
+		return err
+	}
+
+	return experiment.Run(func(ctx context.Context) error {
+		if details.MIGAction != migActionDelete && details.MIGAction != migActionRecreate {
+			return fmt.Errorf("MIG_ACTION must be %q or %q, got %q", migActionDelete, migActionRecreate, details.MIGAction)
+		}
+
+		mig, err := details.mig()
+		if err != nil {
+			return err
+		}
+
+		svc, err := experiment.computeService(ctx, details.GCPCredentialDetails)
+		if err != nil {
+			return err
+		}
+
+		if details.ChaosInterval.Duration > 0 && !experiment.DryRun() {
+			return experiment.Iterate(ctx, func(ctx context.Context, iteration int) error {
+				return experiment.deleteMIGInstances(ctx, svc, mig, details)
+			})
+		}
+
+		return experiment.deleteMIGInstances(ctx, svc, mig, details)
+	})
+}
+
+// deleteMIGInstances picks instances of the group, deletes or recreates them and waits for the group to become stable.
+// In dry-run mode, the permissions are checked and the action which would have been taken is reported in the chaos
+// result instead.
+func (e *Experiment) deleteMIGInstances(ctx context.Context, svc *compute.Service, mig gcpMIG, d *gcpMIGInstanceDeleteDetails) error {
+	instances, err := e.selectMIGInstances(ctx, svc, mig, d.InstanceCount, d.MIGAffectedPerc)
+	if err != nil {
+		return err
+	}
+
+	urls := make([]string, len(instances))
+	names := make([]string, len(instances))
+	for i, instance := range instances {
+		urls[i] = instance.Instance
+		names[i] = managedInstanceName(instance)
+	}
+
+	if e.DryRun() {
+		if err := checkMIGInstances(ctx, svc, mig.project, instances); err != nil {
+			return err
+		}
+
+		log.InfoWithValues(fmt.Sprintf("[Dry-run]: would %s instances", d.MIGAction), map[string]interface{}{
+			"experiment":  e.ChaosDetails.ExperimentName,
+			"group":       mig.String(),
+			"instances":   strings.Join(names, ", "),
+			"permissions": permissionInstancesDelete,
+		})
+		e.updateResult(reasonDryRun, fmt.Sprintf("dry-run: would %s instances %s of group %s", d.MIGAction, strings.Join(names, ", "), mig), eventTypeNormal)
+
+		return nil
+	}
+
+	if d.MIGAction == migActionDelete {
+		err = mig.deleteInstances(ctx, svc, urls)
+	} else {
+		err = mig.recreateInstances(ctx, svc, urls)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		e.SetTarget(name, targetKindInstance, targetStatusInjected)
+	}
+
+	return mig.waitForStable(ctx, svc, d.MIGStableTimeout.Duration)
+}
+
+// selectMIGInstances picks a random subset of the instances of the group, which are not being changed by the group,
+// either by count or by percentage. The instances are logged and recorded in the chaos result.
+func (e *Experiment) selectMIGInstances(ctx context.Context, svc *compute.Service, mig gcpMIG, count, perc int) ([]*compute.ManagedInstance, error) {
+	all, err := mig.instances(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []*compute.ManagedInstance
+	for _, instance := range all {
+		if instance.CurrentAction == managedInstanceActionNone && instance.InstanceStatus == instanceStatusRunning {
+			instances = append(instances, instance)
+		}
+	}
+
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("group %s has no running instances", mig)
+	}
+
+	n, err := affectedCount(len(instances), fmt.Sprintf("%d running instances of group %s", len(instances), mig), count, perc)
+	if err != nil {
+		return nil, err
+	}
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	rnd.Shuffle(len(instances), func(i, j int) {
+		instances[i], instances[j] = instances[j], instances[i]
+	})
+	instances = instances[:n]
+
+	names := make([]string, len(instances))
+	for i, instance := range instances {
+		names[i] = managedInstanceName(instance)
+		e.SetTarget(names[i], targetKindInstance, targetStatusTargeted)
+	}
+
+	log.InfoWithValues("targeting instances", map[string]interface{}{
+		"experiment": e.ChaosDetails.ExperimentName,
+		"group":      mig.String(),
+		"instances":  strings.Join(names, ", "),
+		"project":    mig.project,
+	})
+
+	return instances, nil
+}
@@ -0,0 +1,145 @@
+package experiments
+
+import (
+	"testing"
+
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCPMIGInstanceDeleteRecreate(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.polls = 2
+	fake.addMIG("europe-west1-b", "mig", "vm-1", "vm-2", "vm-3")
+
+	result, err := runLocal(t, fake, GCPMIGInstanceDelete, map[string]string{
+		"GCP_MIG":        "mig",
+		"GCP_PROJECT":    "project",
+		"GCP_ZONE":       "europe-west1-b",
+		"INSTANCE_COUNT": "2",
+	})
+	assert.NoError(t, err)
+
+	instances, size := fake.migInstances("europe-west1-b", "mig")
+	assert.Len(t, instances, 3)
+	assert.Equal(t, int64(3), size)
+	assert.Equal(t, []string{"europe-west1-b/mig"}, fake.called("recreateInstances"))
+	assert.Len(t, fake.called("get"), 2)
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	status := targetStatus(result)
+	assert.Len(t, status, 2)
+	for _, s := range status {
+		assert.Equal(t, targetStatusInjected, s)
+	}
+}
+
+func TestGCPMIGInstanceDeleteRegional(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.addMIG("europe-west1", "mig", "vm-1", "vm-2", "vm-3", "vm-4")
+
+	result, err := runLocal(t, fake, GCPMIGInstanceDelete, map[string]string{
+		"GCP_MIG":           "mig",
+		"GCP_PROJECT":       "project",
+		"GCP_REGION":        "europe-west1",
+		"MIG_AFFECTED_PERC": "50",
+		"MIG_ACTION":        "delete",
+	})
+	assert.NoError(t, err)
+
+	instances, size := fake.migInstances("europe-west1", "mig")
+	assert.Len(t, instances, 2)
+	assert.Equal(t, int64(2), size)
+	assert.Equal(t, []string{"europe-west1/mig"}, fake.called("deleteInstances"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	for _, instance := range instances {
+		assert.NotContains(t, targetStatus(result), instance)
+	}
+}
+
+func TestGCPMIGInstanceDeleteNotStable(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.polls = 1000
+	fake.addMIG("europe-west1-b", "mig", "vm-1")
+
+	result, err := runLocal(t, fake, GCPMIGInstanceDelete, map[string]string{
+		"GCP_MIG":            "mig",
+		"GCP_PROJECT":        "project",
+		"GCP_ZONE":           "europe-west1-b",
+		"MIG_STABLE_TIMEOUT": "50ms",
+	})
+	assert.ErrorContains(t, err, "group europe-west1-b/mig did not become stable within 50ms")
+
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+	assert.Equal(t, "chaos", result.FailStep)
+}
+
+func TestGCPMIGInstanceDeleteDryRun(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.addMIG("europe-west1-b", "mig", "vm-1")
+
+	result, err := runLocal(t, fake, GCPMIGInstanceDelete, map[string]string{
+		"DRY_RUN":     "true",
+		"GCP_MIG":     "mig",
+		"GCP_PROJECT": "project",
+		"GCP_ZONE":    "europe-west1-b",
+	})
+	assert.NoError(t, err)
+
+	assert.Empty(t, fake.called("recreateInstances"))
+	assert.Equal(t, []string{"europe-west1-b/vm-1"}, fake.called("testIamPermissions"))
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	assert.Contains(t, reasons(&LocalBackend{result: result}), reasonDryRun)
+	assert.Equal(t, map[string]string{"europe-west1-b/vm-1": targetStatusTargeted}, targetStatus(result))
+}
+
+func TestGCPMIGInstanceDeleteDryRunMissingPermissions(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.addMIG("europe-west1-b", "mig", "vm-1")
+	fake.permissions = nil
+
+	result, err := runLocal(t, fake, GCPMIGInstanceDelete, map[string]string{
+		"DRY_RUN":     "true",
+		"GCP_MIG":     "mig",
+		"GCP_PROJECT": "project",
+		"GCP_ZONE":    "europe-west1-b",
+	})
+	assert.EqualError(t, err, "failed to chaos: instance europe-west1-b/vm-1: missing permissions compute.instances.delete")
+
+	assert.Empty(t, fake.called("recreateInstances"))
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+}
+
+func TestGCPMIGInstanceDeleteInvalid(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.addMIG("europe-west1-b", "mig", "vm-1")
+
+	for name, tc := range map[string]struct {
+		env map[string]string
+		err string
+	}{
+		"location": {
+			env: map[string]string{"GCP_REGION": "europe-west1", "GCP_ZONE": "europe-west1-b"},
+			err: "failed to chaos: exactly one of GCP_ZONE or GCP_REGION is required",
+		},
+		"action": {
+			env: map[string]string{"GCP_ZONE": "europe-west1-b", "MIG_ACTION": "stop"},
+			err: `failed to chaos: MIG_ACTION must be "delete" or "recreate", got "stop"`,
+		},
+		"count": {
+			env: map[string]string{"GCP_ZONE": "europe-west1-b", "INSTANCE_COUNT": "2"},
+			err: "failed to chaos: INSTANCE_COUNT 2 exceeds the 1 running instances of group europe-west1-b/mig",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tc.env["GCP_MIG"] = "mig"
+			tc.env["GCP_PROJECT"] = "project"
+
+			_, err := runLocal(t, fake, GCPMIGInstanceDelete, tc.env)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+
+	assert.Empty(t, fake.called("recreateInstances"))
+}
@@ -0,0 +1,117 @@
+package experiments
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jaconi-io/litmus/environment"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+)
+
+func init() {
+	Experiments.Register(Definition{
+		Name:        "gcp-mig-resize",
+		Description: "Reduce the target size of a managed instance group",
+		Details:     &gcpMIGResizeDetails{},
+		Defaults:    map[string]string{"TOTAL_CHAOS_DURATION": "60"},
+		Run:         GCPMIGResize,
+	})
+}
+
+// gcpMIGResizeDetails extend the default experiment details.
+type gcpMIGResizeDetails struct {
+	environment.ExperimentDetails
+	GCPMIGDetails
+	GCPCredentialDetails
+	InstanceCount   int `default:"1" desc:"The number of instances to remove from the target size" split_words:"true"`
+	MIGAffectedPerc int `default:"0" desc:"The percentage of the target size to remove (takes precedence over INSTANCE_COUNT)" envconfig:"MIG_AFFECTED_PERC"`
+}
+
+// GCPMIGResize reduces the target size of a managed instance group for the chaos duration, simulating a loss of
+// capacity. The original target size is restored afterwards, or if the experiment is aborted or fails. Groups managed
+// by an autoscaler should not be targeted, as the autoscaler overrides the target size.
+func GCPMIGResize(opts Options) error {
+	details := &gcpMIGResizeDetails{}
+	experiment, err := NewExperiment("gcp-mig-resize", opts, details)
+	if err != nil {
+		return err
+	}
+
+	return experiment.Run(func(ctx context.Context) error {
+		mig, err := details.mig()
+		if err != nil {
+			return err
+		}
+
+		svc, err := experiment.computeService(ctx, details.GCPCredentialDetails)
+		if err != nil {
+			return err
+		}
+
+		manager, err := mig.get(ctx, svc)
+		if err != nil {
+			return err
+		}
+
+		original := manager.TargetSize
+		if original == 0 {
+			return fmt.Errorf("group %s has a target size of 0", mig)
+		}
+
+		n, err := affectedCount(int(original), fmt.Sprintf("target size %d of group %s", original, mig), details.InstanceCount, details.MIGAffectedPerc)
+		if err != nil {
+			return err
+		}
+
+		size := original - int64(n)
+
+		experiment.SetTarget(mig.String(), targetKindMIG, targetStatusTargeted)
+		log.InfoWithValues("targeting group", map[string]interface{}{
+			"experiment": experiment.ChaosDetails.ExperimentName,
+			"group":      mig.String(),
+			"project":    mig.project,
+			"size":       size,
+			"targetSize": original,
+		})
+
+		if experiment.DryRun() {
+			// Groups have no IAM policy of their own, so the permission to resize is checked on the project.
+			rm, err := experiment.resourceManagerService(ctx, details.GCPCredentialDetails)
+			if err != nil {
+				return err
+			}
+
+			if err := checkProject(ctx, rm, mig.project, []string{permissionInstanceGroupManagersUpdate}); err != nil {
+				return fmt.Errorf("project %s: %w", mig.project, err)
+			}
+
+			experiment.updateResult(reasonDryRun, fmt.Sprintf("dry-run: would resize group %s from %d to %d", mig, original, size), eventTypeNormal)
+			return nil
+		}
+
+		// Registered before resizing, so the size is restored if the experiment is aborted while resizing.
+		experiment.AddRevert(fmt.Sprintf("resize group %s to %d", mig, original), func(ctx context.Context) error {
+			if err := mig.resize(ctx, svc, original); err != nil {
+				return err
+			}
+
+			if err := mig.waitForStable(ctx, svc, details.MIGStableTimeout.Duration); err != nil {
+				return err
+			}
+
+			experiment.SetTarget(mig.String(), targetKindMIG, targetStatusReverted)
+			return nil
+		})
+
+		if err := mig.resize(ctx, svc, size); err != nil {
+			return err
+		}
+
+		experiment.SetTarget(mig.String(), targetKindMIG, targetStatusInjected)
+		if err := mig.waitForStable(ctx, svc, details.MIGStableTimeout.Duration); err != nil {
+			return err
+		}
+
+		return experiment.holdAndRevert(ctx)
+	})
+}
@@ -0,0 +1,116 @@
+package experiments
+
+import (
+	"testing"
+
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCPMIGResize(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.addMIG("europe-west1", "mig", "vm-1", "vm-2", "vm-3", "vm-4")
+
+	result, err := runLocal(t, fake, GCPMIGResize, map[string]string{
+		"GCP_MIG":              "mig",
+		"GCP_PROJECT":          "project",
+		"GCP_REGION":           "europe-west1",
+		"MIG_AFFECTED_PERC":    "50",
+		"TOTAL_CHAOS_DURATION": "10ms",
+	})
+	assert.NoError(t, err)
+
+	instances, size := fake.migInstances("europe-west1", "mig")
+	assert.Len(t, instances, 4)
+	assert.Equal(t, int64(4), size)
+	assert.Equal(t, []string{"europe-west1/mig", "europe-west1/mig"}, fake.called("resize"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	assert.Equal(t, map[string]string{"europe-west1/mig": targetStatusReverted}, targetStatus(result))
+}
+
+func TestGCPMIGResizeAbort(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.addMIG("europe-west1-b", "mig", "vm-1", "vm-2")
+	fake.polls = 1000
+
+	result, err := runLocal(t, fake, GCPMIGResize, map[string]string{
+		"GCP_MIG":              "mig",
+		"GCP_PROJECT":          "project",
+		"GCP_ZONE":             "europe-west1-b",
+		"MIG_STABLE_TIMEOUT":   "50ms",
+		"TOTAL_CHAOS_DURATION": "1h",
+	})
+	assert.ErrorContains(t, err, "group europe-west1-b/mig did not become stable within 50ms")
+
+	// The original size is restored, although the group did not become stable.
+	_, size := fake.migInstances("europe-west1-b", "mig")
+	assert.Equal(t, int64(2), size)
+	assert.Len(t, fake.called("resize"), 2)
+
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+	// Waiting for the restored size failed as well and is recorded with the failed step.
+	assert.Equal(t, "chaos (revert: failed to resize group europe-west1-b/mig to 2: group europe-west1-b/mig did not become stable within 50ms: context deadline exceeded)", result.FailStep)
+}
+
+func TestGCPMIGResizeDryRun(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.addMIG("europe-west1-b", "mig", "vm-1", "vm-2")
+
+	result, err := runLocal(t, fake, GCPMIGResize, map[string]string{
+		"DRY_RUN":     "true",
+		"GCP_MIG":     "mig",
+		"GCP_PROJECT": "project",
+		"GCP_ZONE":    "europe-west1-b",
+	})
+	assert.NoError(t, err)
+
+	assert.Empty(t, fake.called("resize"))
+	assert.Equal(t, []string{"project"}, fake.called("testProjectIamPermissions"))
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	assert.Contains(t, result.Events, LocalEvent{
+		Time:    result.Events[1].Time,
+		Kind:    eventKindResult,
+		Reason:  reasonDryRun,
+		Message: "dry-run: would resize group europe-west1-b/mig from 2 to 1",
+		Type:    eventTypeNormal,
+	})
+}
+
+func TestGCPMIGResizeDryRunPermissions(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.addMIG("europe-west1-b", "mig", "vm-1", "vm-2")
+	fake.permissions = []string{permissionInstancesDelete}
+
+	result, err := runLocal(t, fake, GCPMIGResize, map[string]string{
+		"DRY_RUN":     "true",
+		"GCP_MIG":     "mig",
+		"GCP_PROJECT": "project",
+		"GCP_ZONE":    "europe-west1-b",
+	})
+	assert.EqualError(t, err, "failed to chaos: project project: missing permissions compute.instanceGroupManagers.update")
+
+	assert.Empty(t, fake.called("resize"))
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+}
+
+func TestGCPMIGResizeFailure(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.addMIG("europe-west1-b", "mig", "vm-1", "vm-2")
+	fake.operationErrors["resize europe-west1-b/mig"] = "boom"
+
+	result, err := runLocal(t, fake, GCPMIGResize, map[string]string{
+		"GCP_MIG":     "mig",
+		"GCP_PROJECT": "project",
+		"GCP_ZONE":    "europe-west1-b",
+	})
+	assert.ErrorContains(t, err, "boom")
+
+	// Restoring the original size has been registered before resizing.
+	_, size := fake.migInstances("europe-west1-b", "mig")
+	assert.Equal(t, int64(2), size)
+	assert.Len(t, fake.called("resize"), 2)
+
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+	assert.Equal(t, "chaos (revert: failed to resize group europe-west1-b/mig to 2: operation operation-2 failed: INJECTED: boom)", result.FailStep)
+}
@@ -0,0 +1,232 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/jaconi-io/litmus/environment"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"google.golang.org/api/compute/v1"
+)
+
+// Action of managed instances, which are not being changed by their group.
+const managedInstanceActionNone = "NONE"
+
+// Kind of managed instance group targets recorded in the chaos result.
+const targetKindMIG = "MIG"
+
+// GCPMIGDetails select the managed instance group targeted by an experiment. Either the zone of a zonal group or the
+// region of a regional group is required. It can be embedded by the specific experiment details.
+type GCPMIGDetails struct {
+	GCPMIG           string               `desc:"The managed instance group" envconfig:"GCP_MIG" required:"true"`
+	GCPProject       string               `desc:"The managed instance group project" required:"true" split_words:"true"`
+	GCPRegion        string               `desc:"The region of a regional managed instance group" split_words:"true"`
+	GCPZone          string               `desc:"The zone of a zonal managed instance group" split_words:"true"`
+	MIGStableTimeout environment.Duration `default:"600" desc:"Time to wait for the managed instance group to become stable in seconds (e.g. 600) or as duration (e.g. 10m)" envconfig:"MIG_STABLE_TIMEOUT"`
+}
+
+// gcpMIG identifies a zonal or regional managed instance group within a project.
+type gcpMIG struct {
+	project string
+	zone    string
+	region  string
+	name    string
+}
+
+func (m gcpMIG) String() string {
+	if m.region != "" {
+		return m.region + "/" + m.name
+	}
+
+	return m.zone + "/" + m.name
+}
+
+// mig returns the configured managed instance group.
+func (d GCPMIGDetails) mig() (gcpMIG, error) {
+	if (d.GCPZone == "") == (d.GCPRegion == "") {
+		return gcpMIG{}, errors.New("exactly one of GCP_ZONE or GCP_REGION is required")
+	}
+
+	return gcpMIG{project: d.GCPProject, zone: d.GCPZone, region: d.GCPRegion, name: d.GCPMIG}, nil
+}
+
+// get the managed instance group.
+func (m gcpMIG) get(ctx context.Context, svc *compute.Service) (*compute.InstanceGroupManager, error) {
+	if m.region != "" {
+		return svc.RegionInstanceGroupManagers.Get(m.project, m.region, m.name).Context(ctx).Do()
+	}
+
+	return svc.InstanceGroupManagers.Get(m.project, m.zone, m.name).Context(ctx).Do()
+}
+
+// instances lists the instances managed by the group.
+func (m gcpMIG) instances(ctx context.Context, svc *compute.Service) ([]*compute.ManagedInstance, error) {
+	var instances []*compute.ManagedInstance
+	if m.region != "" {
+		err := svc.RegionInstanceGroupManagers.ListManagedInstances(m.project, m.region, m.name).Pages(ctx, func(resp *compute.RegionInstanceGroupManagersListInstancesResponse) error {
+			instances = append(instances, resp.ManagedInstances...)
+			return nil
+		})
+
+		return instances, err
+	}
+
+	err := svc.InstanceGroupManagers.ListManagedInstances(m.project, m.zone, m.name).Pages(ctx, func(resp *compute.InstanceGroupManagersListManagedInstancesResponse) error {
+		instances = append(instances, resp.ManagedInstances...)
+		return nil
+	})
+
+	return instances, err
+}
+
+// deleteInstances deletes instances (by URL) of the group and waits for the operation to finish. The target size of
+// the group is reduced accordingly.
+func (m gcpMIG) deleteInstances(ctx context.Context, svc *compute.Service, instances []string) error {
+	if m.region != "" {
+		op, err := svc.RegionInstanceGroupManagers.DeleteInstances(m.project, m.region, m.name, &compute.RegionInstanceGroupManagersDeleteInstancesRequest{
+			Instances: instances,
+		}).Context(ctx).Do()
+
+		return m.wait(ctx, svc, op, err)
+	}
+
+	op, err := svc.InstanceGroupManagers.DeleteInstances(m.project, m.zone, m.name, &compute.InstanceGroupManagersDeleteInstancesRequest{
+		Instances: instances,
+	}).Context(ctx).Do()
+
+	return m.wait(ctx, svc, op, err)
+}
+
+// recreateInstances recreates instances (by URL) of the group and waits for the operation to finish.
+func (m gcpMIG) recreateInstances(ctx context.Context, svc *compute.Service, instances []string) error {
+	if m.region != "" {
+		op, err := svc.RegionInstanceGroupManagers.RecreateInstances(m.project, m.region, m.name, &compute.RegionInstanceGroupManagersRecreateRequest{
+			Instances: instances,
+		}).Context(ctx).Do()
+
+		return m.wait(ctx, svc, op, err)
+	}
+
+	op, err := svc.InstanceGroupManagers.RecreateInstances(m.project, m.zone, m.name, &compute.InstanceGroupManagersRecreateInstancesRequest{
+		Instances: instances,
+	}).Context(ctx).Do()
+
+	return m.wait(ctx, svc, op, err)
+}
+
+// resize changes the target size of the group and waits for the operation to finish. The group creates or deletes
+// instances afterwards.
+func (m gcpMIG) resize(ctx context.Context, svc *compute.Service, size int64) error {
+	if m.region != "" {
+		op, err := svc.RegionInstanceGroupManagers.Resize(m.project, m.region, m.name, size).Context(ctx).Do()
+		return m.wait(ctx, svc, op, err)
+	}
+
+	op, err := svc.InstanceGroupManagers.Resize(m.project, m.zone, m.name, size).Context(ctx).Do()
+	return m.wait(ctx, svc, op, err)
+}
+
+// wait for an operation on the group to finish, unless starting the operation failed.
+func (m gcpMIG) wait(ctx context.Context, svc *compute.Service, op *compute.Operation, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if m.region != "" {
+		return waitForRegionOperation(ctx, svc, m.project, m.region, op)
+	}
+
+	return waitForZoneOperation(ctx, svc, m.project, m.zone, op)
+}
+
+// waitForStable blocks until the group reports to be stable, i.e. all instances have been created, deleted or
+// updated as intended. An error is returned if the group did not become stable within the timeout or the context was
+// cancelled.
+func (m gcpMIG) waitForStable(ctx context.Context, svc *compute.Service, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	backoff := operationMinBackoff
+	for {
+		manager, err := m.get(ctx, svc)
+		if err != nil && ctx.Err() != nil {
+			return fmt.Errorf("group %s did not become stable within %s: %w", m, timeout, ctx.Err())
+		} else if err != nil {
+			log.ErrorWithValues(fmt.Sprintf("failed to get group status: %v", err), map[string]interface{}{
+				"group": m.String(),
+			})
+		} else if manager.Status != nil && manager.Status.IsStable {
+			return nil
+		} else {
+			log.InfoWithValues("waiting for group to become stable", map[string]interface{}{
+				"group":      m.String(),
+				"targetSize": manager.TargetSize,
+			})
+		}
+
+		if err := sleep(ctx, backoff); err != nil {
+			return fmt.Errorf("group %s did not become stable within %s: %w", m, timeout, err)
+		}
+
+		backoff *= 2
+		if backoff > operationMaxBackoff {
+			backoff = operationMaxBackoff
+		}
+	}
+}
+
+// managedInstance returns the zone and name of a managed instance from its URL.
+func managedInstance(instance *compute.ManagedInstance) gcpInstance {
+	// The URL ends with zones/<zone>/instances/<name>.
+	name := path.Base(instance.Instance)
+	zone := path.Base(path.Dir(path.Dir(instance.Instance)))
+
+	return gcpInstance{zone: zone, name: name}
+}
+
+// managedInstanceName returns the zone and name of a managed instance (zone/name) from its URL.
+func managedInstanceName(instance *compute.ManagedInstance) string {
+	return managedInstance(instance).String()
+}
+
+// checkMIGInstances checks that each managed instance is running and that the permission to delete it has been
+// granted. Groups have no IAM policy of their own, so the permissions are checked on the instances.
+func checkMIGInstances(ctx context.Context, svc *compute.Service, project string, instances []*compute.ManagedInstance) error {
+	for _, instance := range instances {
+		i := managedInstance(instance)
+		if err := checkInstance(ctx, svc, project, i.zone, i.name, []string{permissionInstancesDelete}); err != nil {
+			return fmt.Errorf("instance %s: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// affectedCount calculates the number of affected instances either by count or by percentage of the available
+// instances. At least one instance is affected, if a percentage is given. The description of the available instances
+// is used in errors.
+func affectedCount(available int, description string, count, perc int) (int, error) {
+	if perc < 0 || perc > 100 {
+		return 0, fmt.Errorf("MIG_AFFECTED_PERC must be between 0 and 100, got %d", perc)
+	} else if perc == 0 && count <= 0 {
+		return 0, fmt.Errorf("INSTANCE_COUNT must be positive, got %d", count)
+	}
+
+	if perc > 0 {
+		n := available * perc / 100
+		if n == 0 {
+			n = 1
+		}
+
+		return n, nil
+	}
+
+	if count > available {
+		return 0, fmt.Errorf("INSTANCE_COUNT %d exceeds the %s", count, description)
+	}
+
+	return count, nil
+}
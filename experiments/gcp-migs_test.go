@@ -0,0 +1,30 @@
+package experiments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffectedCount(t *testing.T) {
+	n, err := affectedCount(4, "4 instances", 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = affectedCount(4, "4 instances", 1, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = affectedCount(3, "3 instances", 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = affectedCount(4, "4 instances", 5, 0)
+	assert.EqualError(t, err, "INSTANCE_COUNT 5 exceeds the 4 instances")
+
+	_, err = affectedCount(4, "4 instances", 0, 0)
+	assert.EqualError(t, err, "INSTANCE_COUNT must be positive, got 0")
+
+	_, err = affectedCount(4, "4 instances", 1, 101)
+	assert.EqualError(t, err, "MIG_AFFECTED_PERC must be between 0 and 100, got 101")
+}
@@ -0,0 +1,55 @@
+package experiments
+
+import (
+	"context"
+
+	"github.com/jaconi-io/litmus/environment"
+)
+
+func init() {
+	Experiments.Register(Definition{
+		Name:        "gcp-vm-maintenance",
+		Description: "Simulate host maintenance events for virtual machine instances",
+		Details:     &gcpVMMaintenanceDetails{},
+		Defaults:    map[string]string{"TOTAL_CHAOS_DURATION": "0"},
+		Run:         GCPVMMaintenance,
+	})
+}
+
+// gcpVMMaintenanceDetails extend the default experiment details.
+type gcpVMMaintenanceDetails struct {
+	environment.ExperimentDetails
+	GCPInstanceDetails
+	GCPCredentialDetails
+}
+
+// GCPVMMaintenance simulates host maintenance events for virtual machine instances. Depending on the maintenance policy
+// (onHostMaintenance), the instances are live migrated or terminated and restarted. The experiment waits for the
+// instances to be running again. If a chaos interval is configured, maintenance events are simulated every interval,
+// until the chaos duration has elapsed.
+func GCPVMMaintenance(opts Options) error {
+	details := &gcpVMMaintenanceDetails{}
+	experiment, err := NewExperiment("gcp-vm-maintenance", opts, details)
+	if err != nil {
+		return err
+	}
+
+	return experiment.Run(func(ctx context.Context) error {
+		svc, err := experiment.computeService(ctx, details.GCPCredentialDetails)
+		if err != nil {
+			return err
+		}
+
+		if experiment.DryRun() {
+			return experiment.dryRunInstances(ctx, svc, details.GCPInstanceDetails, "simulate maintenance events for", permissionInstancesSimulateMaintenanceEvent)
+		}
+
+		if details.ChaosInterval.Duration > 0 {
+			return experiment.Iterate(ctx, func(ctx context.Context, iteration int) error {
+				return experiment.maintainInstances(ctx, svc, details.GCPInstanceDetails)
+			})
+		}
+
+		return experiment.maintainInstances(ctx, svc, details.GCPInstanceDetails)
+	})
+}
@@ -0,0 +1,73 @@
+package experiments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCPVMMaintenance(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.polls = 2
+	fake.add("zone-a", "vm-1", instanceStatusRunning, map[string]string{"maintenance": "terminate"})
+	fake.add("zone-a", "vm-2", instanceStatusRunning, map[string]string{"maintenance": "terminate"})
+
+	result, err := runLocal(t, fake, GCPVMMaintenance, map[string]string{
+		"GCP_INSTANCE_LABELS": "maintenance=terminate",
+		"GCP_PROJECT":         "project",
+		"GCP_ZONE":            "zone-a",
+		"SEQUENCE":            "serial",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-2"))
+	assert.ElementsMatch(t, []string{"zone-a/vm-1", "zone-a/vm-2"}, fake.called("simulateMaintenanceEvent"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	assert.Equal(t, map[string]string{
+		"zone-a/vm-1": targetStatusInjected,
+		"zone-a/vm-2": targetStatusInjected,
+	}, targetStatus(result))
+}
+
+func TestGCPVMMaintenanceNotRunning(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.errors["get zone-a/vm-1"] = 503
+
+	timeout := operationTimeout
+	operationTimeout = 50 * time.Millisecond
+	t.Cleanup(func() {
+		operationTimeout = timeout
+	})
+
+	result, err := runLocal(t, fake, GCPVMMaintenance, map[string]string{
+		"GCP_INSTANCE": "vm-1",
+		"GCP_PROJECT":  "project",
+		"GCP_ZONE":     "zone-a",
+	})
+	assert.ErrorContains(t, err, "instance vm-1 did not become RUNNING")
+
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+	assert.Equal(t, "chaos", result.FailStep)
+}
+
+func TestGCPVMMaintenanceDryRun(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+
+	result, err := runLocal(t, fake, GCPVMMaintenance, map[string]string{
+		"DRY_RUN":      "true",
+		"GCP_INSTANCE": "vm-1",
+		"GCP_PROJECT":  "project",
+		"GCP_ZONE":     "zone-a",
+	})
+	assert.NoError(t, err)
+
+	assert.Empty(t, fake.called("simulateMaintenanceEvent"))
+	assert.Equal(t, []string{"zone-a/vm-1"}, fake.called("testIamPermissions"))
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+}
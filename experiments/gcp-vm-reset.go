@@ -0,0 +1,54 @@
+package experiments
+
+import (
+	"context"
+
+	"github.com/jaconi-io/litmus/environment"
+)
+
+func init() {
+	Experiments.Register(Definition{
+		Name:        "gcp-vm-reset",
+		Description: "Reset (hard power-cycle) virtual machine instances",
+		Details:     &gcpVMResetDetails{},
+		Defaults:    map[string]string{"TOTAL_CHAOS_DURATION": "0"},
+		Run:         GCPVMReset,
+	})
+}
+
+// gcpVMResetDetails extend the default experiment details.
+type gcpVMResetDetails struct {
+	environment.ExperimentDetails
+	GCPInstanceDetails
+	GCPCredentialDetails
+}
+
+// GCPVMReset resets virtual machine instances without a graceful shutdown of the guest operating system, simulating a
+// crash. The experiment waits for the instances to be running again. If a chaos interval is configured, the instances
+// are reset every interval, until the chaos duration has elapsed.
+func GCPVMReset(opts Options) error {
+	details := &gcpVMResetDetails{}
+	experiment, err := NewExperiment("gcp-vm-reset", opts, details)
+	if err != nil {
+		return err
+	}
+
+	return experiment.Run(func(ctx context.Context) error {
+		svc, err := experiment.computeService(ctx, details.GCPCredentialDetails)
+		if err != nil {
+			return err
+		}
+
+		if experiment.DryRun() {
+			return experiment.dryRunInstances(ctx, svc, details.GCPInstanceDetails, "reset", permissionInstancesReset)
+		}
+
+		if details.ChaosInterval.Duration > 0 {
+			return experiment.Iterate(ctx, func(ctx context.Context, iteration int) error {
+				return experiment.resetInstances(ctx, svc, details.GCPInstanceDetails)
+			})
+		}
+
+		return experiment.resetInstances(ctx, svc, details.GCPInstanceDetails)
+	})
+}
@@ -0,0 +1,66 @@
+package experiments
+
+import (
+	"testing"
+
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCPVMReset(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.add("zone-a", "vm-2", instanceStatusRunning, nil)
+
+	result, err := runLocal(t, fake, GCPVMReset, map[string]string{
+		"GCP_INSTANCE": "vm-1,vm-2",
+		"GCP_PROJECT":  "project",
+		"GCP_ZONE":     "zone-a",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-2"))
+	assert.ElementsMatch(t, []string{"zone-a/vm-1", "zone-a/vm-2"}, fake.called("reset"))
+	assert.Empty(t, fake.called("stop"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	assert.Equal(t, map[string]string{
+		"zone-a/vm-1": targetStatusInjected,
+		"zone-a/vm-2": targetStatusInjected,
+	}, targetStatus(result))
+}
+
+func TestGCPVMResetFailure(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.operationErrors["reset zone-a/vm-1"] = "boom"
+
+	result, err := runLocal(t, fake, GCPVMReset, map[string]string{
+		"GCP_INSTANCE": "vm-1",
+		"GCP_PROJECT":  "project",
+		"GCP_ZONE":     "zone-a",
+	})
+	assert.Error(t, err)
+
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+	assert.Equal(t, "chaos", result.FailStep)
+	assert.Equal(t, map[string]string{"zone-a/vm-1": targetStatusTargeted}, targetStatus(result))
+}
+
+func TestGCPVMResetInterval(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+
+	result, err := runLocal(t, fake, GCPVMReset, map[string]string{
+		"CHAOS_INTERVAL":       "500ms",
+		"GCP_INSTANCE":         "vm-1",
+		"GCP_PROJECT":          "project",
+		"GCP_ZONE":             "zone-a",
+		"TOTAL_CHAOS_DURATION": "1s",
+	})
+	assert.NoError(t, err)
+
+	assert.Len(t, fake.called("reset"), 2)
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+}
@@ -4,43 +4,60 @@ import (
 	"context"
 
 	"github.com/jaconi-io/litmus/environment"
-	"google.golang.org/api/compute/v1"
-
-	clients "github.com/litmuschaos/litmus-go/pkg/clients"
 )
 
+func init() {
+	Experiments.Register(Definition{
+		Name:        "gcp-vm-restart",
+		Description: "Restart virtual machine instances",
+		Details:     &gcpVMRestartDetails{},
+		Defaults:    map[string]string{"TOTAL_CHAOS_DURATION": "60"},
+		Run:         GCPVMRestart,
+	})
+}
+
 // gcpVMRestartDetails extend the default experiment details.
 type gcpVMRestartDetails struct {
 	environment.ExperimentDetails
-	GCPInstance string `required:"true" split_words:"true"`
-	GCPProject  string `required:"true" split_words:"true"`
-	GCPZone     string `required:"true" split_words:"true"`
+	GCPInstanceDetails
+	GCPCredentialDetails
 }
 
-// GCPVMRestart restarts a virtual machine instance.
-func GCPVMRestart(clients clients.ClientSets) error {
+// GCPVMRestart restarts virtual machine instances. The instances are kept stopped for the chaos duration. If a chaos
+// interval is configured, the instances are restarted every interval instead, until the chaos duration has elapsed.
+// Instances selected by labels or filter are picked anew for each restart.
+func GCPVMRestart(opts Options) error {
 	details := &gcpVMRestartDetails{}
-	experiment, err := NewExperiment("gcp-vm-restart", clients, details)
+	experiment, err := NewExperiment("gcp-vm-restart", opts, details)
 	if err != nil {
 		return err
 	}
 
 	return experiment.Run(func(ctx context.Context) error {
-		svc, err := compute.NewService(ctx)
+		svc, err := experiment.computeService(ctx, details.GCPCredentialDetails)
 		if err != nil {
 			return err
 		}
 
-		_, err = svc.Instances.Stop(details.GCPProject, details.GCPZone, details.GCPInstance).Context(ctx).Do()
-		if err != nil {
-			return err
+		if experiment.DryRun() {
+			return experiment.dryRunInstances(ctx, svc, details.GCPInstanceDetails, "restart", permissionInstancesStop, permissionInstancesStart)
 		}
 
-		_, err = svc.Instances.Start(details.GCPProject, details.GCPZone, details.GCPInstance).Context(ctx).Do()
-		if err != nil {
+		if details.ChaosInterval.Duration > 0 {
+			return experiment.Iterate(ctx, func(ctx context.Context, iteration int) error {
+				err := experiment.stopInstances(ctx, svc, details.GCPInstanceDetails)
+				if revertErr := experiment.Revert(); err == nil {
+					err = revertErr
+				}
+
+				return err
+			})
+		}
+
+		if err := experiment.stopInstances(ctx, svc, details.GCPInstanceDetails); err != nil {
 			return err
 		}
 
-		return nil
+		return experiment.holdAndRevert(ctx)
 	})
 }
@@ -0,0 +1,79 @@
+package experiments
+
+import (
+	"testing"
+
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCPVMRestart(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.polls = 2
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.add("zone-a", "vm-2", instanceStatusRunning, nil)
+
+	result, err := runLocal(t, fake, GCPVMRestart, map[string]string{
+		"TOTAL_CHAOS_DURATION": "10ms",
+		"GCP_INSTANCE":         "vm-1,vm-2",
+		"GCP_PROJECT":          "project",
+		"GCP_ZONE":             "zone-a",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-2"))
+	assert.ElementsMatch(t, []string{"zone-a/vm-1", "zone-a/vm-2"}, fake.called("stop"))
+	assert.ElementsMatch(t, []string{"zone-a/vm-1", "zone-a/vm-2"}, fake.called("start"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	assert.Equal(t, map[string]string{
+		"zone-a/vm-1": targetStatusReverted,
+		"zone-a/vm-2": targetStatusReverted,
+	}, targetStatus(result))
+}
+
+func TestGCPVMRestartStartFailure(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.operationErrors["start zone-a/vm-1"] = "boom"
+
+	result, err := runLocal(t, fake, GCPVMRestart, map[string]string{
+		"TOTAL_CHAOS_DURATION": "10ms",
+		"GCP_INSTANCE":         "vm-1",
+		"GCP_PROJECT":          "project",
+		"GCP_ZONE":             "zone-a",
+	})
+	assert.Error(t, err)
+
+	assert.Equal(t, "TERMINATED", fake.status("zone-a", "vm-1"))
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+	assert.Equal(t, "chaos (revert: failed to start instance zone-a/vm-1: operation operation-3 failed: INJECTED: boom)", result.FailStep)
+}
+
+func TestGCPVMRestartInterval(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, map[string]string{"pool": "flapping"})
+	fake.add("zone-a", "vm-2", instanceStatusRunning, map[string]string{"pool": "flapping"})
+
+	result, err := runLocal(t, fake, GCPVMRestart, map[string]string{
+		"CHAOS_INTERVAL":         "500ms",
+		"GCP_INSTANCE_LABELS":    "pool=flapping",
+		"GCP_PROJECT":            "project",
+		"GCP_ZONE":               "zone-a",
+		"INSTANCE_AFFECTED_PERC": "50",
+		"TOTAL_CHAOS_DURATION":   "1500ms",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-2"))
+	assert.Len(t, fake.called("stop"), 3)
+	assert.Equal(t, fake.called("stop"), fake.called("start"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	status := targetStatus(result)
+	assert.Equal(t, iterationStatusPassed, status["iteration-1"])
+	assert.Equal(t, iterationStatusPassed, status["iteration-2"])
+	assert.Equal(t, iterationStatusPassed, status["iteration-3"])
+}
@@ -4,38 +4,53 @@ import (
 	"context"
 
 	"github.com/jaconi-io/litmus/environment"
-	"google.golang.org/api/compute/v1"
-
-	clients "github.com/litmuschaos/litmus-go/pkg/clients"
 )
 
+func init() {
+	Experiments.Register(Definition{
+		Name:        "gcp-vm-stop",
+		Description: "Stop virtual machine instances",
+		Details:     &gcpVMStopDetails{},
+		Defaults:    map[string]string{"TOTAL_CHAOS_DURATION": "0"},
+		Run:         GCPVMStop,
+	})
+}
+
 // gcpVMStopDetails extend the default experiment details.
 type gcpVMStopDetails struct {
 	environment.ExperimentDetails
-	GCPInstance string `required:"true" split_words:"true"`
-	GCPProject  string `required:"true" split_words:"true"`
-	GCPZone     string `required:"true" split_words:"true"`
+	GCPInstanceDetails
+	GCPCredentialDetails
 }
 
-// GCPVMStop stops a virtual machine instance.
-func GCPVMStop(clients clients.ClientSets) error {
+// GCPVMStop stops virtual machine instances. The instances are started again if the experiment is aborted or fails.
+// If a chaos duration is configured, the instances are started again after the chaos duration.
+func GCPVMStop(opts Options) error {
 	details := &gcpVMStopDetails{}
-	experiment, err := NewExperiment("gcp-vm-stop", clients, details)
+	experiment, err := NewExperiment("gcp-vm-stop", opts, details)
 	if err != nil {
 		return err
 	}
 
 	return experiment.Run(func(ctx context.Context) error {
-		svc, err := compute.NewService(ctx)
+		svc, err := experiment.computeService(ctx, details.GCPCredentialDetails)
 		if err != nil {
 			return err
 		}
 
-		_, err = svc.Instances.Stop(details.GCPProject, details.GCPZone, details.GCPInstance).Context(ctx).Do()
-		if err != nil {
+		if experiment.DryRun() {
+			return experiment.dryRunInstances(ctx, svc, details.GCPInstanceDetails, "stop", permissionInstancesStop, permissionInstancesStart)
+		}
+
+		if err := experiment.stopInstances(ctx, svc, details.GCPInstanceDetails); err != nil {
 			return err
 		}
 
-		return nil
+		// Keep the instances stopped, if no chaos duration is configured.
+		if details.ChaosDuration.Duration == 0 {
+			return nil
+		}
+
+		return experiment.holdAndRevert(ctx)
 	})
 }
@@ -0,0 +1,174 @@
+package experiments
+
+import (
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+// runLocal runs an experiment against the fake Compute Engine API using the local backend. The environment variables
+// are set for the duration of the test.
+func runLocal(t *testing.T, fake *fakeCompute, run func(Options) error, env map[string]string) (LocalResult, error) {
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	backend := &LocalBackend{Dir: t.TempDir()}
+	err := run(Options{
+		Backend:                backend,
+		ComputeOptions:         fake.options(),
+		ResourceManagerOptions: fake.resourceManagerOptions(),
+	})
+	return backend.result, err
+}
+
+// targetStatus returns the chaos status of the targets by name.
+func targetStatus(result LocalResult) map[string]string {
+	status := map[string]string{}
+	for _, target := range result.Targets {
+		status[target.Name] = target.ChaosStatus
+	}
+
+	return status
+}
+
+func TestGCPVMStop(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.add("zone-b", "vm-2", instanceStatusRunning, nil)
+
+	result, err := runLocal(t, fake, GCPVMStop, map[string]string{
+		"GCP_INSTANCE": "vm-1,vm-2",
+		"GCP_PROJECT":  "project",
+		"GCP_ZONE":     "zone-a,zone-b",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "TERMINATED", fake.status("zone-a", "vm-1"))
+	assert.Equal(t, "TERMINATED", fake.status("zone-b", "vm-2"))
+	assert.Empty(t, fake.called("start"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	assert.Equal(t, v1alpha1.ResultPhaseCompleted, result.Phase)
+	assert.Equal(t, map[string]string{
+		"zone-a/vm-1": targetStatusInjected,
+		"zone-b/vm-2": targetStatusInjected,
+	}, targetStatus(result))
+}
+
+func TestGCPVMStopChaosDuration(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+
+	result, err := runLocal(t, fake, GCPVMStop, map[string]string{
+		"TOTAL_CHAOS_DURATION": "10ms",
+		"GCP_INSTANCE":         "vm-1",
+		"GCP_PROJECT":          "project",
+		"GCP_ZONE":             "zone-a",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, []string{"zone-a/vm-1"}, fake.called("stop"))
+	assert.Equal(t, []string{"zone-a/vm-1"}, fake.called("start"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	assert.Equal(t, map[string]string{"zone-a/vm-1": targetStatusReverted}, targetStatus(result))
+}
+
+func TestGCPVMStopFailure(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.add("zone-a", "vm-2", instanceStatusRunning, nil)
+	fake.errors["stop zone-a/vm-2"] = http.StatusForbidden
+
+	result, err := runLocal(t, fake, GCPVMStop, map[string]string{
+		"GCP_INSTANCE": "vm-1,vm-2",
+		"GCP_PROJECT":  "project",
+		"GCP_ZONE":     "zone-a",
+		"SEQUENCE":     "serial",
+	})
+	assert.Error(t, err)
+
+	// The stopped instance is started again.
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-2"))
+	assert.Equal(t, []string{"zone-a/vm-1"}, fake.called("start"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+	assert.Equal(t, "chaos", result.FailStep)
+}
+
+func TestGCPVMStopAbort(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.polls = 50
+
+	// Abort while waiting for the instance to stop.
+	fake.hooks["stop zone-a/vm-1"] = func() {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+			t.Error(err)
+		}
+	}
+
+	result, err := runLocal(t, fake, GCPVMStop, map[string]string{
+		"GCP_INSTANCE": "vm-1",
+		"GCP_PROJECT":  "project",
+		"GCP_ZONE":     "zone-a",
+	})
+	assert.ErrorContains(t, err, "experiment aborted")
+
+	// The instance is started again once it has been stopped.
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, []string{"zone-a/vm-1"}, fake.called("start"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictStopped, result.Verdict)
+	assert.Equal(t, map[string]string{"zone-a/vm-1": targetStatusReverted}, targetStatus(result))
+}
+
+func TestGCPVMStopDryRun(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+
+	result, err := runLocal(t, fake, GCPVMStop, map[string]string{
+		"DRY_RUN":      "true",
+		"GCP_INSTANCE": "vm-1",
+		"GCP_PROJECT":  "project",
+		"GCP_ZONE":     "zone-a",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Empty(t, fake.called("stop"))
+	assert.Equal(t, []string{"zone-a/vm-1"}, fake.called("testIamPermissions"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	assert.Contains(t, result.Events, LocalEvent{
+		Time:    result.Events[1].Time,
+		Kind:    eventKindResult,
+		Reason:  reasonDryRun,
+		Message: "dry-run: would stop instances zone-a/vm-1",
+		Type:    eventTypeNormal,
+	})
+}
+
+func TestGCPVMStopDryRunMissingPermissions(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.permissions = []string{permissionInstancesStop}
+
+	result, err := runLocal(t, fake, GCPVMStop, map[string]string{
+		"DRY_RUN":      "true",
+		"GCP_INSTANCE": "vm-1",
+		"GCP_PROJECT":  "project",
+		"GCP_ZONE":     "zone-a",
+	})
+	assert.EqualError(t, err, "failed to chaos: instance zone-a/vm-1: missing permissions compute.instances.start")
+
+	assert.Empty(t, fake.called("stop"))
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+}
@@ -0,0 +1,52 @@
+package experiments
+
+import (
+	"context"
+
+	"github.com/jaconi-io/litmus/environment"
+)
+
+func init() {
+	Experiments.Register(Definition{
+		Name:        "gcp-vm-suspend",
+		Description: "Suspend virtual machine instances",
+		Details:     &gcpVMSuspendDetails{},
+		Defaults:    map[string]string{"TOTAL_CHAOS_DURATION": "60"},
+		Run:         GCPVMSuspend,
+	})
+}
+
+// gcpVMSuspendDetails extend the default experiment details.
+type gcpVMSuspendDetails struct {
+	environment.ExperimentDetails
+	GCPInstanceDetails
+	GCPCredentialDetails
+}
+
+// GCPVMSuspend suspends virtual machine instances for the chaos duration and resumes them afterwards. Unlike stopped
+// instances, suspended instances keep their memory state, so the guest operating system experiences a freeze (e.g.
+// clocks jump and connections go stale). The instances are resumed if the experiment is aborted or fails.
+func GCPVMSuspend(opts Options) error {
+	details := &gcpVMSuspendDetails{}
+	experiment, err := NewExperiment("gcp-vm-suspend", opts, details)
+	if err != nil {
+		return err
+	}
+
+	return experiment.Run(func(ctx context.Context) error {
+		svc, err := experiment.computeService(ctx, details.GCPCredentialDetails)
+		if err != nil {
+			return err
+		}
+
+		if experiment.DryRun() {
+			return experiment.dryRunInstances(ctx, svc, details.GCPInstanceDetails, "suspend", permissionInstancesSuspend, permissionInstancesResume)
+		}
+
+		if err := experiment.suspendInstances(ctx, svc, details.GCPInstanceDetails); err != nil {
+			return err
+		}
+
+		return experiment.holdAndRevert(ctx)
+	})
+}
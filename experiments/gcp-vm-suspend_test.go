@@ -0,0 +1,73 @@
+package experiments
+
+import (
+	"testing"
+
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCPVMSuspend(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.polls = 2
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.add("zone-b", "vm-2", instanceStatusRunning, nil)
+
+	result, err := runLocal(t, fake, GCPVMSuspend, map[string]string{
+		"TOTAL_CHAOS_DURATION": "10ms",
+		"GCP_INSTANCE":         "vm-1,vm-2",
+		"GCP_PROJECT":          "project",
+		"GCP_ZONE":             "zone-a,zone-b",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-b", "vm-2"))
+	assert.ElementsMatch(t, []string{"zone-a/vm-1", "zone-b/vm-2"}, fake.called("suspend"))
+	assert.ElementsMatch(t, []string{"zone-a/vm-1", "zone-b/vm-2"}, fake.called("resume"))
+	assert.Empty(t, fake.called("stop"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	assert.Equal(t, map[string]string{
+		"zone-a/vm-1": targetStatusReverted,
+		"zone-b/vm-2": targetStatusReverted,
+	}, targetStatus(result))
+}
+
+func TestGCPVMSuspendFailure(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.add("zone-a", "vm-2", instanceStatusRunning, nil)
+	fake.operationErrors["suspend zone-a/vm-2"] = "boom"
+
+	result, err := runLocal(t, fake, GCPVMSuspend, map[string]string{
+		"TOTAL_CHAOS_DURATION": "1h",
+		"GCP_INSTANCE":         "vm-1,vm-2",
+		"GCP_PROJECT":          "project",
+		"GCP_ZONE":             "zone-a",
+	})
+	assert.Error(t, err)
+
+	// The suspended instance is resumed, although the chaos duration has not elapsed.
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-2"))
+	assert.Equal(t, []string{"zone-a/vm-1"}, fake.called("resume"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+	assert.Equal(t, "chaos", result.FailStep)
+}
+
+func TestGCPVMSuspendDryRun(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.permissions = []string{permissionInstancesSuspend}
+
+	_, err := runLocal(t, fake, GCPVMSuspend, map[string]string{
+		"DRY_RUN":      "true",
+		"GCP_INSTANCE": "vm-1",
+		"GCP_PROJECT":  "project",
+		"GCP_ZONE":     "zone-a",
+	})
+	assert.EqualError(t, err, "failed to chaos: instance zone-a/vm-1: missing permissions compute.instances.resume")
+	assert.Empty(t, fake.called("suspend"))
+}
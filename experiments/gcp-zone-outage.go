@@ -0,0 +1,193 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jaconi-io/litmus/environment"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"google.golang.org/api/compute/v1"
+)
+
+func init() {
+	Experiments.Register(Definition{
+		Name:        "gcp-zone-outage",
+		Description: "Stop all virtual machine instances in a zone",
+		Details:     &gcpZoneOutageDetails{},
+		Defaults:    map[string]string{"TOTAL_CHAOS_DURATION": "60"},
+		Run:         GCPZoneOutage,
+	})
+}
+
+// gcpZoneOutageDetails extend the default experiment details.
+type gcpZoneOutageDetails struct {
+	environment.ExperimentDetails
+	GCPCredentialDetails
+	Concurrency       int    `default:"10" desc:"The maximum number of instances stopped or started at the same time"`
+	GCPInstanceFilter string `desc:"Only stop running instances matching this filter expression" split_words:"true"`
+	GCPInstanceLabels string `desc:"Only stop running instances with these labels (comma separated key=value pairs)" split_words:"true"`
+	GCPProject        string `desc:"The project" required:"true" split_words:"true"`
+	GCPZone           string `desc:"The zone to take down" required:"true" split_words:"true"`
+	MaxInstances      int    `default:"10" desc:"Fail without stopping any instance, if more running instances match (safety cap)" split_words:"true"`
+}
+
+// GCPZoneOutage simulates the outage of a zone by stopping all running instances in the zone, optionally restricted
+// by labels or filter expression. The instances are stopped with bounded concurrency and kept stopped for the chaos
+// duration. Exactly the stopped instances are started again afterwards, or if the experiment is aborted or fails. No
+// instance is stopped, if more instances match than allowed by the safety cap.
+func GCPZoneOutage(opts Options) error {
+	details := &gcpZoneOutageDetails{}
+	experiment, err := NewExperiment("gcp-zone-outage", opts, details)
+	if err != nil {
+		return err
+	}
+
+	return experiment.Run(func(ctx context.Context) error {
+		if details.MaxInstances <= 0 {
+			return fmt.Errorf("MAX_INSTANCES must be positive, got %d", details.MaxInstances)
+		} else if details.Concurrency <= 0 {
+			return fmt.Errorf("CONCURRENCY must be positive, got %d", details.Concurrency)
+		}
+
+		svc, err := experiment.computeService(ctx, details.GCPCredentialDetails)
+		if err != nil {
+			return err
+		}
+
+		instances, err := experiment.selectZoneInstances(ctx, svc, details)
+		if err != nil {
+			return err
+		}
+
+		if experiment.DryRun() {
+			return experiment.dryRunZoneOutage(ctx, svc, details, instances)
+		}
+
+		// Instances are added once stopping them is requested, so they are started if the experiment is aborted meanwhile.
+		var mu sync.Mutex
+		stopped := map[gcpInstance]*compute.Operation{}
+		experiment.AddRevert("start instances in zone "+details.GCPZone, func(ctx context.Context) error {
+			mu.Lock()
+			var instances []gcpInstance
+			ops := stopped
+			for instance := range stopped {
+				instances = append(instances, instance)
+			}
+			stopped = map[gcpInstance]*compute.Operation{}
+			mu.Unlock()
+
+			return forEachInstanceLimit(ctx, details.Concurrency, instances, func(ctx context.Context, instance gcpInstance) error {
+				// The instance might still be stopping. There is nothing to start, if stopping failed.
+				var failed *operationFailedError
+				err := waitForZoneOperation(ctx, svc, details.GCPProject, instance.zone, ops[instance])
+				if errors.As(err, &failed) {
+					return nil
+				} else if err == nil {
+					err = startInstance(ctx, svc, details.GCPProject, instance.zone, instance.name)
+				}
+
+				if err != nil {
+					// Keep the instance, so it is started when the revert is retried.
+					mu.Lock()
+					stopped[instance] = ops[instance]
+					mu.Unlock()
+
+					return err
+				}
+
+				experiment.SetTarget(instance.String(), targetKindInstance, targetStatusReverted)
+				return nil
+			})
+		})
+
+		err = forEachInstanceLimit(ctx, details.Concurrency, instances, func(ctx context.Context, instance gcpInstance) error {
+			op, err := requestStop(ctx, svc, details.GCPProject, instance.zone, instance.name)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			stopped[instance] = op
+			mu.Unlock()
+
+			if err := waitForZoneOperation(ctx, svc, details.GCPProject, instance.zone, op); err != nil {
+				return err
+			}
+
+			experiment.SetTarget(instance.String(), targetKindInstance, targetStatusInjected)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return experiment.holdAndRevert(ctx)
+	})
+}
+
+// selectZoneInstances lists the running instances in the zone matching the labels and filter expression. An error is
+// returned, if no instance or more instances than allowed by the safety cap match. The instances are logged and
+// recorded in the chaos result.
+func (e *Experiment) selectZoneInstances(ctx context.Context, svc *compute.Service, d *gcpZoneOutageDetails) ([]gcpInstance, error) {
+	filter, err := GCPInstanceDetails{GCPInstanceFilter: d.GCPInstanceFilter, GCPInstanceLabels: d.GCPInstanceLabels}.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := runningInstances(ctx, svc, d.GCPProject, d.GCPZone, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("no running instances in zone %s match filter %q", d.GCPZone, filter)
+	} else if len(instances) > d.MaxInstances {
+		return nil, fmt.Errorf("%d running instances in zone %s match filter %q, exceeding MAX_INSTANCES %d", len(instances), d.GCPZone, filter, d.MaxInstances)
+	}
+
+	names := make([]string, len(instances))
+	for i, instance := range instances {
+		names[i] = instance.String()
+		e.SetTarget(instance.String(), targetKindInstance, targetStatusTargeted)
+	}
+
+	log.InfoWithValues("targeting instances", map[string]interface{}{
+		"experiment": e.ChaosDetails.ExperimentName,
+		"instances":  strings.Join(names, ", "),
+		"project":    d.GCPProject,
+		"zone":       d.GCPZone,
+	})
+
+	return instances, nil
+}
+
+// dryRunZoneOutage checks that each instance is running and that the permissions to stop and start it have been
+// granted. The instances are not changed; instead, the action which would have been taken is reported in the chaos
+// result.
+func (e *Experiment) dryRunZoneOutage(ctx context.Context, svc *compute.Service, d *gcpZoneOutageDetails, instances []gcpInstance) error {
+	permissions := []string{permissionInstancesStop, permissionInstancesStart}
+	err := forEachInstanceLimit(ctx, d.Concurrency, instances, func(ctx context.Context, instance gcpInstance) error {
+		return checkInstance(ctx, svc, d.GCPProject, instance.zone, instance.name, permissions)
+	})
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(instances))
+	for i, instance := range instances {
+		names[i] = instance.String()
+	}
+
+	log.InfoWithValues("[Dry-run]: would stop instances", map[string]interface{}{
+		"experiment":  e.ChaosDetails.ExperimentName,
+		"instances":   strings.Join(names, ", "),
+		"permissions": strings.Join(permissions, ", "),
+		"project":     d.GCPProject,
+	})
+	e.updateResult(reasonDryRun, fmt.Sprintf("dry-run: would stop %d instances in zone %s: %s", len(instances), d.GCPZone, strings.Join(names, ", ")), eventTypeNormal)
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package experiments
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+// addZone adds running instances vm-1, vm-2 and vm-3 of the web tier, a running instance db-1 of the db tier and a
+// terminated instance vm-4 of the web tier.
+func addZone(fake *fakeCompute) {
+	web := map[string]string{"tier": "web"}
+	fake.add("zone-a", "vm-1", instanceStatusRunning, web)
+	fake.add("zone-a", "vm-2", instanceStatusRunning, web)
+	fake.add("zone-a", "vm-3", instanceStatusRunning, web)
+	fake.add("zone-a", "vm-4", "TERMINATED", web)
+	fake.add("zone-a", "db-1", instanceStatusRunning, map[string]string{"tier": "db"})
+	fake.add("zone-b", "vm-5", instanceStatusRunning, web)
+}
+
+func TestGCPZoneOutage(t *testing.T) {
+	fake := newFakeCompute(t)
+	addZone(fake)
+
+	result, err := runLocal(t, fake, GCPZoneOutage, map[string]string{
+		"CONCURRENCY":          "2",
+		"GCP_INSTANCE_LABELS":  "tier=web",
+		"GCP_PROJECT":          "project",
+		"GCP_ZONE":             "zone-a",
+		"TOTAL_CHAOS_DURATION": "10ms",
+	})
+	assert.NoError(t, err)
+
+	stopped := []string{"zone-a/vm-1", "zone-a/vm-2", "zone-a/vm-3"}
+	assert.ElementsMatch(t, stopped, fake.called("stop"))
+	assert.ElementsMatch(t, stopped, fake.called("start"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, "TERMINATED", fake.status("zone-a", "vm-4"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictPassed, result.Verdict)
+	assert.Equal(t, map[string]string{
+		"zone-a/vm-1": targetStatusReverted,
+		"zone-a/vm-2": targetStatusReverted,
+		"zone-a/vm-3": targetStatusReverted,
+	}, targetStatus(result))
+}
+
+func TestGCPZoneOutageSafetyCap(t *testing.T) {
+	fake := newFakeCompute(t)
+	addZone(fake)
+
+	result, err := runLocal(t, fake, GCPZoneOutage, map[string]string{
+		"GCP_PROJECT":   "project",
+		"GCP_ZONE":      "zone-a",
+		"MAX_INSTANCES": "3",
+	})
+	assert.EqualError(t, err, `failed to chaos: 4 running instances in zone zone-a match filter "", exceeding MAX_INSTANCES 3`)
+
+	assert.Empty(t, fake.called("stop"))
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+}
+
+func TestGCPZoneOutageStopFailure(t *testing.T) {
+	fake := newFakeCompute(t)
+	addZone(fake)
+	fake.operationErrors["stop zone-a/vm-2"] = "boom"
+
+	result, err := runLocal(t, fake, GCPZoneOutage, map[string]string{
+		"GCP_INSTANCE_LABELS":  "tier=web",
+		"GCP_PROJECT":          "project",
+		"GCP_ZONE":             "zone-a",
+		"TOTAL_CHAOS_DURATION": "1h",
+	})
+	assert.Error(t, err)
+
+	// Only the stopped instances are started again.
+	assert.ElementsMatch(t, []string{"zone-a/vm-1", "zone-a/vm-3"}, fake.called("start"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-2"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-3"))
+
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, result.Verdict)
+	assert.Equal(t, "chaos", result.FailStep)
+}
+
+func TestGCPZoneOutageAbort(t *testing.T) {
+	fake := newFakeCompute(t)
+	addZone(fake)
+	fake.polls = 50
+
+	// Abort while waiting for vm-1 to stop. Instances are stopped one at a time.
+	fake.hooks["stop zone-a/vm-1"] = func() {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+			t.Error(err)
+		}
+	}
+
+	result, err := runLocal(t, fake, GCPZoneOutage, map[string]string{
+		"CONCURRENCY":         "1",
+		"GCP_INSTANCE_LABELS": "tier=web",
+		"GCP_PROJECT":         "project",
+		"GCP_ZONE":            "zone-a",
+	})
+	assert.ErrorContains(t, err, "experiment aborted")
+
+	// Exactly the stopped instances are started again, including vm-1 once it has been stopped.
+	assert.Contains(t, fake.called("start"), "zone-a/vm-1")
+	assert.ElementsMatch(t, fake.called("stop"), fake.called("start"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-2"))
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-3"))
+	assert.Equal(t, v1alpha1.ResultVerdictStopped, result.Verdict)
+}
+
+func TestGCPZoneOutageDryRun(t *testing.T) {
+	fake := newFakeCompute(t)
+	addZone(fake)
+
+	result, err := runLocal(t, fake, GCPZoneOutage, map[string]string{
+		"DRY_RUN":             "true",
+		"GCP_INSTANCE_LABELS": "tier=db",
+		"GCP_PROJECT":         "project",
+		"GCP_ZONE":            "zone-a",
+	})
+	assert.NoError(t, err)
+
+	assert.Empty(t, fake.called("stop"))
+	assert.Equal(t, []string{"zone-a/db-1"}, fake.called("testIamPermissions"))
+	assert.Contains(t, result.Events, LocalEvent{
+		Time:    result.Events[1].Time,
+		Kind:    eventKindResult,
+		Reason:  reasonDryRun,
+		Message: "dry-run: would stop 1 instances in zone zone-a: zone-a/db-1",
+		Type:    eventTypeNormal,
+	})
+}
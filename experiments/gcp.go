@@ -0,0 +1,360 @@
+package experiments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Operation status reported by the Compute Engine API once an operation has finished.
+const operationStatusDone = "DONE"
+
+// IAM permissions required to change virtual machine instances and managed instance groups.
+const (
+	permissionInstanceGroupManagersUpdate       = "compute.instanceGroupManagers.update"
+	permissionInstancesDelete                   = "compute.instances.delete"
+	permissionInstancesReset                    = "compute.instances.reset"
+	permissionInstancesResume                   = "compute.instances.resume"
+	permissionInstancesSimulateMaintenanceEvent = "compute.instances.simulateMaintenanceEvent"
+	permissionInstancesStart                    = "compute.instances.start"
+	permissionInstancesStop                     = "compute.instances.stop"
+	permissionInstancesSuspend                  = "compute.instances.suspend"
+)
+
+// Settings for waiting on Compute Engine operations. These are variables, so they can be adjusted in tests.
+var (
+	operationTimeout    = 10 * time.Minute
+	operationMinBackoff = time.Second
+	operationMaxBackoff = 30 * time.Second
+)
+
+// waitForZoneOperation blocks until the zone operation is done. An error is returned if the operation did not finish
+// in time, the context was cancelled or the operation itself reported an error.
+func waitForZoneOperation(ctx context.Context, svc *compute.Service, project, zone string, op *compute.Operation) error {
+	return waitForOperation(ctx, op, map[string]interface{}{"zone": zone}, func(ctx context.Context, name string) (*compute.Operation, error) {
+		return svc.ZoneOperations.Wait(project, zone, name).Context(ctx).Do()
+	}, func(ctx context.Context, name string) (*compute.Operation, error) {
+		return svc.ZoneOperations.Get(project, zone, name).Context(ctx).Do()
+	})
+}
+
+// waitForRegionOperation blocks until the region operation is done. An error is returned if the operation did not
+// finish in time, the context was cancelled or the operation itself reported an error.
+func waitForRegionOperation(ctx context.Context, svc *compute.Service, project, region string, op *compute.Operation) error {
+	return waitForOperation(ctx, op, map[string]interface{}{"region": region}, func(ctx context.Context, name string) (*compute.Operation, error) {
+		return svc.RegionOperations.Wait(project, region, name).Context(ctx).Do()
+	}, func(ctx context.Context, name string) (*compute.Operation, error) {
+		return svc.RegionOperations.Get(project, region, name).Context(ctx).Do()
+	})
+}
+
+// waitForOperation blocks until the operation is done using the given functions to wait for and get the operation.
+// The values identify the location of the operation in logs.
+func waitForOperation(ctx context.Context, op *compute.Operation, values map[string]interface{}, wait, get func(context.Context, string) (*compute.Operation, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+
+	name := op.Name
+	backoff := operationMinBackoff
+	for op.Status != operationStatusDone {
+		log.InfoWithValues("waiting for operation", withValues(values, map[string]interface{}{
+			"operation": name,
+			"status":    op.Status,
+		}))
+
+		// Wait returns as soon as the operation is done, or after a server side deadline at the latest. Fall back to
+		// Get, if waiting failed (e.g. because of a transient error).
+		next, err := wait(ctx, name)
+		if err != nil {
+			next, err = get(ctx, name)
+		}
+
+		if err != nil && ctx.Err() != nil {
+			return fmt.Errorf("operation %s did not finish: %w", name, ctx.Err())
+		} else if err != nil {
+			log.ErrorWithValues(fmt.Sprintf("failed to get operation status: %v", err), withValues(values, map[string]interface{}{
+				"operation": name,
+			}))
+		} else {
+			op = next
+		}
+
+		if op.Status == operationStatusDone {
+			break
+		}
+
+		if err := sleep(ctx, backoff); err != nil {
+			return fmt.Errorf("operation %s did not finish: %w", name, err)
+		}
+
+		backoff *= 2
+		if backoff > operationMaxBackoff {
+			backoff = operationMaxBackoff
+		}
+	}
+
+	return operationError(op)
+}
+
+// withValues merges log values. The given values are not modified.
+func withValues(values, more map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(values)+len(more))
+	for k, v := range values {
+		merged[k] = v
+	}
+
+	for k, v := range more {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+// operationError converts the errors reported by a finished operation into a single error. Nil is returned, if the
+// operation succeeded.
+func operationError(op *compute.Operation) error {
+	if op.Error == nil || len(op.Error.Errors) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, len(op.Error.Errors))
+	for i, e := range op.Error.Errors {
+		msgs[i] = fmt.Sprintf("%s: %s", e.Code, e.Message)
+	}
+
+	return &operationFailedError{name: op.Name, msg: strings.Join(msgs, "; ")}
+}
+
+// operationFailedError is returned for operations, which finished but reported errors. The change requested by such an
+// operation has not been made.
+type operationFailedError struct {
+	name string
+	msg  string
+}
+
+func (e *operationFailedError) Error() string {
+	return fmt.Sprintf("operation %s failed: %s", e.name, e.msg)
+}
+
+// sleep pauses for the given duration. An error is returned, if the context is done before the duration elapsed.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// computeService creates a Compute Engine API client authenticated with the given credentials. The client options of
+// the experiment take precedence.
+func (e *Experiment) computeService(ctx context.Context, credentials GCPCredentialDetails) (*compute.Service, error) {
+	opts, err := e.clientOptions(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return compute.NewService(ctx, append(opts, e.computeOptions...)...)
+}
+
+// resourceManagerService creates a Cloud Resource Manager API client authenticated with the given credentials. The
+// client options of the experiment take precedence.
+func (e *Experiment) resourceManagerService(ctx context.Context, credentials GCPCredentialDetails) (*cloudresourcemanager.Service, error) {
+	opts, err := e.clientOptions(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return cloudresourcemanager.NewService(ctx, append(opts, e.resourceManagerOptions...)...)
+}
+
+// clientOptions authenticate Google Cloud API clients with the given credentials.
+func (e *Experiment) clientOptions(ctx context.Context, credentials GCPCredentialDetails) ([]option.ClientOption, error) {
+	// Clients are not available in local mode. Avoid passing a typed nil pointer as interface.
+	var kubeClient kubernetes.Interface
+	if e.Clients.KubeClient != nil {
+		kubeClient = e.Clients.KubeClient
+	}
+
+	return credentials.clientOptions(ctx, kubeClient, e.ChaosDetails.ChaosNamespace)
+}
+
+// instanceRequest requests a change of a virtual machine instance. The returned operation is not waited for.
+type instanceRequest func(ctx context.Context, svc *compute.Service, project, zone, instance string) (*compute.Operation, error)
+
+// requestStop requests to stop a virtual machine instance.
+func requestStop(ctx context.Context, svc *compute.Service, project, zone, instance string) (*compute.Operation, error) {
+	return svc.Instances.Stop(project, zone, instance).Context(ctx).Do()
+}
+
+// requestStart requests to start a virtual machine instance.
+func requestStart(ctx context.Context, svc *compute.Service, project, zone, instance string) (*compute.Operation, error) {
+	return svc.Instances.Start(project, zone, instance).Context(ctx).Do()
+}
+
+// requestSuspend requests to suspend a virtual machine instance. The memory state of the instance is preserved.
+func requestSuspend(ctx context.Context, svc *compute.Service, project, zone, instance string) (*compute.Operation, error) {
+	return svc.Instances.Suspend(project, zone, instance).Context(ctx).Do()
+}
+
+// requestResume requests to resume a suspended virtual machine instance.
+func requestResume(ctx context.Context, svc *compute.Service, project, zone, instance string) (*compute.Operation, error) {
+	return svc.Instances.Resume(project, zone, instance).Context(ctx).Do()
+}
+
+// changeInstance requests a change of a virtual machine instance and waits for the operation to finish.
+func changeInstance(ctx context.Context, svc *compute.Service, project, zone, instance string, request instanceRequest) error {
+	op, err := request(ctx, svc, project, zone, instance)
+	if err != nil {
+		return err
+	}
+
+	return waitForZoneOperation(ctx, svc, project, zone, op)
+}
+
+// stopInstance stops a virtual machine instance and waits for the operation to finish.
+func stopInstance(ctx context.Context, svc *compute.Service, project, zone, instance string) error {
+	return changeInstance(ctx, svc, project, zone, instance, requestStop)
+}
+
+// startInstance starts a virtual machine instance and waits for the operation to finish.
+func startInstance(ctx context.Context, svc *compute.Service, project, zone, instance string) error {
+	return changeInstance(ctx, svc, project, zone, instance, requestStart)
+}
+
+// resetInstance resets a virtual machine instance like pressing the reset button of a physical machine. The guest
+// operating system is not shut down gracefully. Waits for the operation to finish and the instance to be running
+// again.
+func resetInstance(ctx context.Context, svc *compute.Service, project, zone, instance string) error {
+	op, err := svc.Instances.Reset(project, zone, instance).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	if err := waitForZoneOperation(ctx, svc, project, zone, op); err != nil {
+		return err
+	}
+
+	return waitForInstanceStatus(ctx, svc, project, zone, instance, instanceStatusRunning)
+}
+
+// simulateMaintenanceEvent simulates a host maintenance event for a virtual machine instance. Depending on its
+// maintenance policy, the instance is either live migrated or terminated and restarted. Waits for the operation to
+// finish and the instance to be running again.
+func simulateMaintenanceEvent(ctx context.Context, svc *compute.Service, project, zone, instance string) error {
+	op, err := svc.Instances.SimulateMaintenanceEvent(project, zone, instance).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	if err := waitForZoneOperation(ctx, svc, project, zone, op); err != nil {
+		return err
+	}
+
+	return waitForInstanceStatus(ctx, svc, project, zone, instance, instanceStatusRunning)
+}
+
+// waitForInstanceStatus blocks until the virtual machine instance has the given status. An error is returned if the
+// instance did not reach the status in time or the context was cancelled.
+func waitForInstanceStatus(ctx context.Context, svc *compute.Service, project, zone, instance, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+
+	backoff := operationMinBackoff
+	for {
+		i, err := svc.Instances.Get(project, zone, instance).Context(ctx).Do()
+		if err != nil && ctx.Err() != nil {
+			return fmt.Errorf("instance %s did not become %s: %w", instance, status, ctx.Err())
+		} else if err != nil {
+			log.ErrorWithValues(fmt.Sprintf("failed to get instance status: %v", err), map[string]interface{}{
+				"instance": instance,
+				"zone":     zone,
+			})
+		} else if i.Status == status {
+			return nil
+		} else {
+			log.InfoWithValues("waiting for instance status", map[string]interface{}{
+				"expected": status,
+				"instance": instance,
+				"status":   i.Status,
+				"zone":     zone,
+			})
+		}
+
+		if err := sleep(ctx, backoff); err != nil {
+			return fmt.Errorf("instance %s did not become %s: %w", instance, status, err)
+		}
+
+		backoff *= 2
+		if backoff > operationMaxBackoff {
+			backoff = operationMaxBackoff
+		}
+	}
+}
+
+// checkInstance verifies that a virtual machine instance exists, is running and that the caller has been granted the
+// given IAM permissions on the instance.
+func checkInstance(ctx context.Context, svc *compute.Service, project, zone, instance string, permissions []string) error {
+	i, err := svc.Instances.Get(project, zone, instance).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	if i.Status != instanceStatusRunning {
+		return fmt.Errorf("instance is %s, expected %s", i.Status, instanceStatusRunning)
+	}
+
+	resp, err := svc.Instances.TestIamPermissions(project, zone, instance, &compute.TestPermissionsRequest{
+		Permissions: permissions,
+	}).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	return missingPermissions(permissions, resp.Permissions)
+}
+
+// checkProject verifies that the caller has been granted the given IAM permissions on the project. This covers
+// resources without an IAM policy of their own (e.g. managed instance groups), as long as the permissions are granted
+// on the project or inherited from its parents.
+func checkProject(ctx context.Context, svc *cloudresourcemanager.Service, project string, permissions []string) error {
+	resp, err := svc.Projects.TestIamPermissions(project, &cloudresourcemanager.TestIamPermissionsRequest{
+		Permissions: permissions,
+	}).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	return missingPermissions(permissions, resp.Permissions)
+}
+
+// missingPermissions returns an error listing the required permissions which have not been granted.
+func missingPermissions(required, granted []string) error {
+	grants := map[string]bool{}
+	for _, permission := range granted {
+		grants[permission] = true
+	}
+
+	var missing []string
+	for _, permission := range required {
+		if !grants[permission] {
+			missing = append(missing, permission)
+		}
+	}
+
+	if len(missing) != 0 {
+		return fmt.Errorf("missing permissions %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
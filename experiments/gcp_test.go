@@ -0,0 +1,89 @@
+package experiments
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopInstance(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.polls = 3
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+
+	err := stopInstance(context.Background(), fake.service(), "project", "zone-a", "vm-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "TERMINATED", fake.status("zone-a", "vm-1"))
+}
+
+func TestStartInstance(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.polls = 0
+	fake.add("zone-a", "vm-1", "TERMINATED", nil)
+
+	err := startInstance(context.Background(), fake.service(), "project", "zone-a", "vm-1")
+	assert.NoError(t, err)
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+}
+
+func TestResetInstance(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.polls = 2
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+
+	err := resetInstance(context.Background(), fake.service(), "project", "zone-a", "vm-1")
+	assert.NoError(t, err)
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+	assert.Equal(t, []string{"zone-a/vm-1", "zone-a/vm-1", "zone-a/vm-1"}, fake.called("get"))
+}
+
+func TestStopInstanceAPIError(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.errors["stop zone-a/vm-1"] = http.StatusForbidden
+
+	err := stopInstance(context.Background(), fake.service(), "project", "zone-a", "vm-1")
+	assert.Error(t, err)
+	assert.Equal(t, instanceStatusRunning, fake.status("zone-a", "vm-1"))
+}
+
+func TestStopInstanceOperationError(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.operationErrors["stop zone-a/vm-1"] = "boom"
+
+	err := stopInstance(context.Background(), fake.service(), "project", "zone-a", "vm-1")
+	assert.EqualError(t, err, "operation operation-1 failed: INJECTED: boom")
+}
+
+func TestStopInstanceTimeout(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.polls = 1000000
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+
+	timeout := operationTimeout
+	operationTimeout = 50 * time.Millisecond
+	defer func() {
+		operationTimeout = timeout
+	}()
+
+	err := stopInstance(context.Background(), fake.service(), "project", "zone-a", "vm-1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCheckInstance(t *testing.T) {
+	fake := newFakeCompute(t)
+	fake.add("zone-a", "vm-1", instanceStatusRunning, nil)
+	fake.add("zone-a", "vm-2", "TERMINATED", nil)
+	fake.permissions = []string{permissionInstancesStop}
+	svc := fake.service()
+	ctx := context.Background()
+
+	assert.NoError(t, checkInstance(ctx, svc, "project", "zone-a", "vm-1", []string{permissionInstancesStop}))
+	assert.EqualError(t, checkInstance(ctx, svc, "project", "zone-a", "vm-1", []string{permissionInstancesStop, permissionInstancesStart}), "missing permissions compute.instances.start")
+	assert.EqualError(t, checkInstance(ctx, svc, "project", "zone-a", "vm-2", nil), "instance is TERMINATED, expected RUNNING")
+	assert.Error(t, checkInstance(ctx, svc, "project", "zone-a", "vm-3", nil))
+}
@@ -4,17 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/jaconi-io/litmus/environment"
 	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
 	clients "github.com/litmuschaos/litmus-go/pkg/clients"
-	"github.com/litmuschaos/litmus-go/pkg/events"
 	"github.com/litmuschaos/litmus-go/pkg/log"
 	"github.com/litmuschaos/litmus-go/pkg/probe"
-	"github.com/litmuschaos/litmus-go/pkg/result"
-	"github.com/litmuschaos/litmus-go/pkg/status"
 	"github.com/litmuschaos/litmus-go/pkg/types"
 	"github.com/litmuschaos/litmus-go/pkg/utils/common"
+	"google.golang.org/api/option"
 )
 
 // Common Kubernetes event types.
@@ -23,14 +28,97 @@ const (
 	eventTypeWarning = "Warning"
 )
 
+// Reason of the event reporting the outcome of a dry-run.
+const reasonDryRun = "DryRun"
+
+// Reason of the events reporting the outcome of a chaos iteration.
+const reasonIteration = "ChaosIteration"
+
+// Reasons of the events reporting the start and end of a ramp period.
+const (
+	reasonRampStart = "RampStart"
+	reasonRampEnd   = "RampEnd"
+)
+
+// Probe phases as understood by litmus.
+const (
+	probePhasePreChaos    = "PreChaos"
+	probePhaseDuringChaos = "DuringChaos"
+	probePhasePostChaos   = "PostChaos"
+)
+
+// Chaos status of targets as recorded in the chaos result.
+const (
+	targetStatusTargeted = "targeted"
+	targetStatusInjected = "injected"
+	targetStatusReverted = "reverted"
+)
+
+// Chaos iterations are recorded as targets of the chaos result with their outcome as status.
+const (
+	targetKindIteration   = "Iteration"
+	iterationStatusPassed = "passed"
+	iterationStatusFailed = "failed"
+)
+
+// Options configure how an experiment is run.
+type Options struct {
+	// Clients used to access the Kubernetes cluster. Clients are not required if a backend other than the Kubernetes
+	// backend is used and the experiment does not run in the context of an engine.
+	Clients clients.ClientSets
+
+	// Backend records the chaos result and events. Defaults to a KubernetesBackend using Clients.
+	Backend Backend
+
+	// DryRun resolves and checks the chaos targets without causing chaos. A dry-run can also be requested by setting
+	// DRY_RUN=true.
+	DryRun bool
+
+	// ComputeOptions configure the Compute Engine API client (e.g. endpoint or credentials).
+	ComputeOptions []option.ClientOption
+
+	// ResourceManagerOptions configure the Cloud Resource Manager API client (e.g. endpoint or credentials).
+	ResourceManagerOptions []option.ClientOption
+}
+
 type Experiment struct {
 	Clients       clients.ClientSets
+	Backend       Backend
 	ChaosDetails  *types.ChaosDetails
 	EventDetails  *types.EventDetails
 	ResultDetails *types.ResultDetails
+
+	// Actions undoing the changes made by the chaos function and the chaos targets. Guarded by mu, as the chaos
+	// function might change several targets concurrently.
+	mu      sync.Mutex
+	reverts []revert
+
+	// Targets set during the current iteration, nil outside of iterations. Guarded by mu.
+	iterationTargets []string
+
+	// Set when the experiment is aborted. Failures of steps are no longer recorded in the chaos result afterwards, as
+	// the result is marked as stopped instead.
+	aborted int32
+
+	// Set while changes made by the chaos function are reverted when a step fails (chaos and post-chaos phase).
+	revertOnFailure bool
+
+	dryRun                 bool
+	computeOptions         []option.ClientOption
+	resourceManagerOptions []option.ClientOption
+	details                environment.ExperimentDetails
 }
 
-func NewExperiment(experimentName string, clients clients.ClientSets, customDetails interface{}) (*Experiment, error) {
+// Maximum duration of a single revert action. This is a variable, so it can be adjusted in tests.
+var revertTimeout = 15 * time.Minute
+
+// revert is a named action undoing a change made by the chaos function.
+type revert struct {
+	step string
+	f    func(context.Context) error
+}
+
+func NewExperiment(experimentName string, opts Options, customDetails interface{}) (*Experiment, error) {
 	// Populate detail structs with values from environment variables.
 	chaosDetails := &types.ChaosDetails{}
 	eventDetails := &types.EventDetails{}
@@ -40,33 +128,112 @@ func NewExperiment(experimentName string, clients clients.ClientSets, customDeta
 		return nil, err
 	}
 
+	details, err := environment.Lookup(customDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	// Probes and engine events require access to the cluster running the engine.
+	if chaosDetails.EngineName != "" && opts.Clients.LitmusClient == nil {
+		return nil, errors.New("running in the context of an engine (CHAOS_ENGINE) requires a Kubernetes cluster")
+	}
+
+	backend := opts.Backend
+	if backend == nil {
+		backend = KubernetesBackend{Clients: opts.Clients}
+	}
+
 	return &Experiment{
-		Clients:       clients,
-		ChaosDetails:  chaosDetails,
-		EventDetails:  eventDetails,
-		ResultDetails: resultDetails,
+		Clients:                opts.Clients,
+		Backend:                backend,
+		ChaosDetails:           chaosDetails,
+		EventDetails:           eventDetails,
+		ResultDetails:          resultDetails,
+		dryRun:                 opts.DryRun || details.DryRun,
+		computeOptions:         opts.ComputeOptions,
+		resourceManagerOptions: opts.ResourceManagerOptions,
+		details:                details,
 	}, nil
 }
 
+// DryRun reports whether the chaos function must only resolve and check its targets without causing chaos.
+func (e *Experiment) DryRun() bool {
+	return e.dryRun
+}
+
+// Run the experiment. The experiment lifecycle is split into phases: pre-chaos, chaos, post-chaos and summary. Each
+// phase runs its probes and generates its engine event exactly once. All steps share a single context, which is
+// cancelled when the experiment is aborted (SIGINT or SIGTERM) or the experiment timeout is exceeded. Once the running
+// step returned, an aborted experiment is recorded as stopped and all changes made by the chaos function are reverted.
 func (e *Experiment) Run(f func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	// Initialize probes when running in the context of an engine.
-	if err := e.runIfInEngineContext("initialize probes", func(context.Context) error {
-		return probe.InitializeProbesInChaosResultDetails(e.ChaosDetails, e.Clients, e.ResultDetails)
-	}); err != nil {
+	if e.details.ExperimentTimeout.Duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, e.details.ExperimentTimeout.Duration)
+		defer cancel()
+	}
+
+	// Watch for the abort signal. The context is cancelled, so steps (e.g. the chaos function) return early.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			atomic.StoreInt32(&e.aborted, 1)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := e.runPhases(ctx, f)
+	if err != nil && atomic.LoadInt32(&e.aborted) != 0 {
+		// Revert first, so reverted targets are part of the recorded result.
+		err = e.revertAfterFailure(fmt.Errorf("experiment aborted: %v", err))
+		e.recordAbort()
 		return err
 	}
 
-	// Change the chaos result state to SOT (Start of Test).
-	if err := e.run("change the chaos result state to SOT", func(context.Context) error {
-		return result.ChaosResult(e.ChaosDetails, e.Clients, e.ResultDetails, "SOT")
+	return err
+}
+
+// Run the phases of the experiment. Changes made by the chaos function are reverted, if a step of the chaos or
+// post-chaos phase fails.
+func (e *Experiment) runPhases(ctx context.Context, f func(context.Context) error) error {
+	if err := e.setup(ctx); err != nil {
+		return err
+	}
+
+	if err := e.preChaos(ctx); err != nil {
+		return err
+	}
+
+	e.revertOnFailure = true
+	if err := e.chaos(ctx, f); err != nil {
+		return err
+	}
+
+	if err := e.postChaos(ctx); err != nil {
+		return err
+	}
+	e.revertOnFailure = false
+
+	return e.summary(ctx)
+}
+
+// Prepare the chaos result.
+func (e *Experiment) setup(ctx context.Context) error {
+	// Initialize probes when running in the context of an engine.
+	if err := e.runIfInEngineContext(ctx, "initialize probes", func(context.Context) error {
+		return probe.InitializeProbesInChaosResultDetails(e.ChaosDetails, e.Clients, e.ResultDetails)
 	}); err != nil {
 		return err
 	}
 
-	// Set the chaos result UID.
-	if err := e.run("set the chaos result UID", func(context.Context) error {
-		return result.SetResultUID(e.ResultDetails, e.Clients, e.ChaosDetails)
+	// Change the chaos result state to SOT (Start of Test).
+	if err := e.run(ctx, "change the chaos result state to SOT", func(context.Context) error {
+		return e.Backend.Result(e.ChaosDetails, e.ResultDetails, resultStateSOT)
 	}); err != nil {
 		return err
 	}
@@ -84,94 +251,124 @@ func (e *Experiment) Run(f func(context.Context) error) error {
 		})
 	}
 
-	// Calling AbortWatcher go routine. It will continuously watch for the abort signal and generate the required
-	// events and result.
-	go common.AbortWatcher(e.ChaosDetails.ExperimentName, e.Clients, e.ResultDetails, e.ChaosDetails, e.EventDetails)
+	return nil
+}
 
-	// Run pre-chaos application status check.
-	if err := e.run("pre-chaos application status check", func(context.Context) error {
-		return status.AUTStatusCheck(e.ChaosDetails.AppDetail.Namespace, e.ChaosDetails.AppDetail.Label, "", e.ChaosDetails.Timeout, e.ChaosDetails.Delay, e.Clients, e.ChaosDetails)
+// Check the application under test and run the pre-chaos probes. Continuous probes are started in this phase.
+func (e *Experiment) preChaos(ctx context.Context) error {
+	if err := e.run(ctx, "pre-chaos application status check", func(context.Context) error {
+		return e.Backend.ApplicationStatus(e.ChaosDetails)
 	}); err != nil {
 		return err
 	}
 
-	if err := e.runIfInEngineContext("pre-chaos probes", func(context.Context) error {
-		// Mark application under test (AUT) as running, as we already checked the status.
-		msg := "AUT: Running"
+	return e.runIfInEngineContext(ctx, "pre-chaos probes", func(context.Context) error {
+		return e.runProbes(probePhasePreChaos, types.PreChaosCheck, "AUT: Running")
+	})
+}
 
-		// Run the probes in the pre-chaos check.
-		if len(e.ResultDetails.ProbeDetails) != 0 {
-			if err := probe.RunProbes(e.ChaosDetails, e.Clients, e.ResultDetails, "PreChaos", e.EventDetails); err != nil {
-				log.Errorf("Probe Failed, err: %v", err)
-				failStep := "Failed while running probes"
-				e.updateEngine(types.PreChaosCheck, "AUT: Running, Probes: Unsuccessful", eventTypeWarning)
-				result.RecordAfterFailure(e.ChaosDetails, e.ResultDetails, failStep, e.Clients, e.EventDetails)
-				return err
-			}
+// Execute the actual chaos. OnChaos probes are started concurrently with the chaos function, while continuous probes
+// (started in the pre-chaos phase) keep running. OnChaos probes run for the chaos duration, so the phase lasts at least
+// the chaos duration if OnChaos probes are configured. Probe verdicts are evaluated by litmus in the post-chaos phase.
+// If a ramp time is configured, the phase waits for the ramp time before injecting chaos and after the chaos function
+// returned.
+func (e *Experiment) chaos(ctx context.Context, f func(context.Context) error) error {
+	if err := e.ramp(ctx, "before chaos"); err != nil {
+		return err
+	}
+
+	if e.ChaosDetails.EngineName != "" {
+		e.updateEngine(types.ChaosInject, fmt.Sprintf("injecting chaos %q", e.ChaosDetails.ExperimentName), eventTypeNormal)
+	}
 
-			msg = "AUT: Running, Probes: Successful"
+	probes := make(chan error, 1)
+	end := time.Now()
+	if e.ChaosDetails.EngineName != "" && len(e.ResultDetails.ProbeDetails) != 0 {
+		if e.hasOnChaosProbes() {
+			end = end.Add(time.Duration(e.ChaosDetails.ChaosDuration) * time.Second)
 		}
 
-		// Generating the events for the pre-chaos probes.
-		e.updateEngine(types.PreChaosCheck, msg, eventTypeNormal)
-		return nil
-	}); err != nil {
-		return err
+		go func() {
+			probes <- probe.RunProbes(e.ChaosDetails, e.Clients, e.ResultDetails, probePhaseDuringChaos, e.EventDetails)
+		}()
+	} else {
+		probes <- nil
 	}
 
-	// Execute the actual chaos.
-	if err := e.run("chaos", f); err != nil {
+	err := e.run(ctx, "chaos", f)
+
+	// Wait until all probes have been started.
+	probeErr := <-probes
+	if err != nil {
 		return err
 	}
 
-	// Run post-chaos application status check.
-	if err := e.run("post-chaos application status check", func(context.Context) error {
-		return status.AUTStatusCheck(e.ChaosDetails.AppDetail.Namespace, e.ChaosDetails.AppDetail.Label, "", e.ChaosDetails.Timeout, e.ChaosDetails.Delay, e.Clients, e.ChaosDetails)
+	if err := e.run(ctx, "during-chaos probes", func(ctx context.Context) error {
+		if probeErr != nil {
+			return probeErr
+		}
+
+		// Let OnChaos probes run for the chaos duration.
+		return sleep(ctx, time.Until(end))
 	}); err != nil {
 		return err
 	}
 
-	if err := e.runIfInEngineContext("post-chaos probes", func(context.Context) error {
-		// Mark application under test (AUT) as running, as we already checked the status.
-		msg := "AUT: Running"
+	return e.ramp(ctx, "after chaos")
+}
 
-		// Run the probes in the pre-chaos check.
-		if len(e.ResultDetails.ProbeDetails) != 0 {
-			if err := probe.RunProbes(e.ChaosDetails, e.Clients, e.ResultDetails, "PostChaos", e.EventDetails); err != nil {
-				e.updateEngine(types.PreChaosCheck, "AUT: Running, Probes: Unsuccessful", eventTypeWarning)
-				return err
-			}
+// Report whether OnChaos probes are configured.
+func (e *Experiment) hasOnChaosProbes() bool {
+	for _, details := range e.ResultDetails.ProbeDetails {
+		if _, ok := details.Status["OnChaos"]; ok {
+			return true
+		}
+	}
+
+	return false
+}
 
-			msg = "AUT: Running, Probes: Successful"
+// Wait for the ramp time (if configured), so traffic and metrics can settle. The start and end of the ramp period are
+// reported as events. Waiting stops early, if the context is done (e.g. because the experiment is aborted).
+func (e *Experiment) ramp(ctx context.Context, when string) error {
+	d := e.details.RampTime.Duration
+	if d == 0 {
+		return nil
+	}
+
+	return e.run(ctx, "ramp time "+when, func(ctx context.Context) error {
+		e.notify(reasonRampStart, fmt.Sprintf("waiting for the %s ramp time %s", d, when), eventTypeNormal)
+		if err := sleep(ctx, d); err != nil {
+			return err
 		}
 
-		// Generating the events for the pre-chaos probes.
-		e.updateEngine(types.PreChaosCheck, msg, eventTypeNormal)
+		e.notify(reasonRampEnd, fmt.Sprintf("the %s ramp time %s elapsed", d, when), eventTypeNormal)
 		return nil
+	})
+}
+
+// Check the application under test and run the post-chaos probes. The verdicts of continuous and OnChaos probes are
+// evaluated in this phase.
+func (e *Experiment) postChaos(ctx context.Context) error {
+	if err := e.run(ctx, "post-chaos application status check", func(context.Context) error {
+		return e.Backend.ApplicationStatus(e.ChaosDetails)
 	}); err != nil {
 		return err
 	}
 
-	if e.ChaosDetails.EngineName != "" {
-		// marking AUT as running, as we already checked the status of application under test
-		msg := "AUT: Running"
-
-		// run the probes in the post-chaos check
-		if len(e.ResultDetails.ProbeDetails) != 0 {
-			if err := probe.RunProbes(e.ChaosDetails, e.Clients, e.ResultDetails, "PostChaos", e.EventDetails); err != nil {
-				e.updateEngine(types.PostChaosCheck, "AUT: Running, Probes: Unsuccessful", eventTypeWarning)
-				return err
-			}
-			msg = "AUT: Running, Probes: Successful"
-		}
+	return e.runIfInEngineContext(ctx, "post-chaos probes", func(context.Context) error {
+		return e.runProbes(probePhasePostChaos, types.PostChaosCheck, "AUT: Running")
+	})
+}
 
-		// generating post chaos event
-		e.updateEngine(types.PostChaosCheck, msg, eventTypeNormal)
-	}
+// Complete the chaos result and report the verdict.
+func (e *Experiment) summary(ctx context.Context) error {
+	// All steps succeeded. The verdict is still changed to failed, if probes failed.
+	types.SetResultAfterCompletion(e.ResultDetails, v1alpha1.ResultVerdictPassed, v1alpha1.ResultPhaseCompleted, "N/A")
 
 	// Change the chaos result state to EOT (End of Test).
-	if err := e.run("change the chaos result state to EOT", func(context.Context) error {
-		return result.ChaosResult(e.ChaosDetails, e.Clients, e.ResultDetails, "EOT")
+	if err := e.run(ctx, "change the chaos result state to EOT", func(context.Context) error {
+		return e.Backend.Result(e.ChaosDetails, e.ResultDetails, resultStateEOT)
 	}); err != nil {
 		return err
 	}
@@ -203,29 +400,279 @@ func (e *Experiment) Run(f func(context.Context) error) error {
 	return nil
 }
 
-func (e Experiment) updateResult(reason, msg, eventType string) {
+// Run the probes of a phase and generate a single engine event with the given reason for the outcome.
+func (e *Experiment) runProbes(phase, reason, msg string) error {
+	if len(e.ResultDetails.ProbeDetails) == 0 {
+		e.updateEngine(reason, msg, eventTypeNormal)
+		return nil
+	}
+
+	if err := probe.RunProbes(e.ChaosDetails, e.Clients, e.ResultDetails, phase, e.EventDetails); err != nil {
+		e.updateEngine(reason, msg+", Probes: Unsuccessful", eventTypeWarning)
+		return err
+	}
+
+	e.updateEngine(reason, msg+", Probes: Successful", eventTypeNormal)
+	return nil
+}
+
+// AddRevert registers an action undoing a change made by the chaos function (e.g. starting a stopped instance).
+// Registered actions are run in reverse order on abort, when the experiment fails or when Revert is called.
+func (e *Experiment) AddRevert(step string, f func(context.Context) error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.reverts = append(e.reverts, revert{step: step, f: f})
+}
+
+// Revert runs all registered revert actions in reverse order. All actions are run, even if some of them fail. Failed
+// actions stay registered, so they are retried by the next call (e.g. after the experiment failed). Each action runs
+// on a context of its own, limited by the revert timeout, so changes are also reverted once the experiment has been
+// aborted or timed out.
+func (e *Experiment) Revert() error {
+	e.mu.Lock()
+	reverts := e.reverts
+	e.reverts = nil
+	e.mu.Unlock()
+
+	var failed []revert
+	var msgs []string
+	for i := len(reverts) - 1; i >= 0; i-- {
+		log.InfoWithValues(fmt.Sprintf("[Revert]: %s", reverts[i].step), map[string]interface{}{
+			"experiment": e.ChaosDetails.ExperimentName,
+		})
+
+		if err := reverts[i].run(); err != nil {
+			msgs = append(msgs, fmt.Sprintf("failed to %s: %v", reverts[i].step, err))
+			failed = append([]revert{reverts[i]}, failed...)
+		}
+	}
+
+	if len(msgs) != 0 {
+		e.mu.Lock()
+		e.reverts = append(failed, e.reverts...)
+		e.mu.Unlock()
+
+		return errors.New(strings.Join(msgs, "; "))
+	}
+
+	return nil
+}
+
+// Run the revert action on a context detached from the experiment.
+// holdAndRevert keeps the changes made by the chaos function for the chaos duration and reverts them afterwards. The
+// changes are reverted once the experiment returns, if the context is done before.
+func (e *Experiment) holdAndRevert(ctx context.Context) error {
+	log.InfoWithValues("waiting for chaos duration", map[string]interface{}{
+		"duration": e.details.ChaosDuration.String(),
+	})
+	if err := sleep(ctx, e.details.ChaosDuration.Duration); err != nil {
+		return err
+	}
+
+	return e.Revert()
+}
+
+func (r revert) run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), revertTimeout)
+	defer cancel()
+
+	return r.f(ctx)
+}
+
+// Iterate runs f repeatedly: at the start of every CHAOS_INTERVAL until TOTAL_CHAOS_DURATION has elapsed since the
+// first iteration, but at least once. Without an interval, f runs exactly once. Iterations are numbered by interval;
+// intervals are skipped, if the previous iteration takes longer than the interval. The outcome of each iteration is
+// recorded in the chaos result and reported as event, listing the targets set during the iteration. The status of a
+// target in the chaos result is overwritten by later iterations. Iterations continue after a failed iteration; the errors of all
+// failed iterations are returned. Waiting for the next iteration stops early, if the context is done.
+func (e *Experiment) Iterate(ctx context.Context, f func(ctx context.Context, iteration int) error) error {
+	interval, duration := e.details.ChaosInterval.Duration, e.details.ChaosDuration.Duration
+	iterations := 1
+	if interval > 0 && duration > interval {
+		iterations = int((duration + interval - 1) / interval)
+	}
+
+	start := time.Now()
+	var msgs []string
+	for i := 1; i <= iterations; {
+		log.InfoWithValues(fmt.Sprintf("[Iteration]: %d of %d", i, iterations), map[string]interface{}{
+			"experiment": e.ChaosDetails.ExperimentName,
+			"interval":   interval.String(),
+		})
+
+		e.mu.Lock()
+		e.iterationTargets = []string{}
+		e.mu.Unlock()
+
+		err := f(ctx, i)
+
+		e.mu.Lock()
+		targets := ""
+		if len(e.iterationTargets) != 0 {
+			targets = fmt.Sprintf(" (targets: %s)", strings.Join(e.iterationTargets, ", "))
+		}
+		e.iterationTargets = nil
+		e.mu.Unlock()
+
+		name := fmt.Sprintf("iteration-%d", i)
+		if err != nil {
+			e.SetTarget(name, targetKindIteration, iterationStatusFailed)
+			e.notify(reasonIteration, fmt.Sprintf("iteration %d of %d failed%s: %v", i, iterations, targets, err), eventTypeWarning)
+			msgs = append(msgs, fmt.Sprintf("iteration %d: %v", i, err))
+		} else {
+			e.SetTarget(name, targetKindIteration, iterationStatusPassed)
+			e.notify(reasonIteration, fmt.Sprintf("iteration %d of %d passed%s", i, iterations, targets), eventTypeNormal)
+		}
+
+		if iterations == 1 {
+			break
+		}
+
+		// The next iteration starts with the first interval which has not started yet.
+		next := int(time.Since(start)/interval) + 2
+		if next > i+1 {
+			log.Warnf("[Iteration]: iteration %d took longer than the interval %v, skipping %d iterations", i, interval, next-i-1)
+		}
+
+		i = next
+		if i > iterations {
+			break
+		}
+
+		if err := sleep(ctx, time.Until(start.Add(time.Duration(i-1)*interval))); err != nil {
+			msgs = append(msgs, fmt.Sprintf("iteration %d: %v", i, err))
+			break
+		}
+	}
+
+	if len(msgs) != 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+
+	return nil
+}
+
+// SetTarget records the chaos status of a target (e.g. a virtual machine instance) in the chaos result. Targets set
+// during an iteration are reported with the outcome of the iteration.
+func (e *Experiment) SetTarget(name, kind, status string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.iterationTargets != nil && kind != targetKindIteration {
+		e.addIterationTarget(name)
+	}
+
+	common.SetTargets(name, status, kind, e.ChaosDetails)
+}
+
+// Add a target to the targets of the current iteration, unless it has been set during the iteration before. The
+// caller must hold mu.
+func (e *Experiment) addIterationTarget(name string) {
+	for _, target := range e.iterationTargets {
+		if target == name {
+			return
+		}
+	}
+
+	e.iterationTargets = append(e.iterationTargets, name)
+}
+
+// Revert all changes after a failed step. Errors while reverting are added to the original error.
+func (e *Experiment) revertAfterFailure(err error) error {
+	if revertErr := e.Revert(); revertErr != nil {
+		return fmt.Errorf("%v (revert: %v)", err, revertErr)
+	}
+
+	return err
+}
+
+// Record a failed step in the chaos result and report the failed verdict.
+func (e *Experiment) recordFailure(step string) {
+	types.SetResultAfterCompletion(e.ResultDetails, v1alpha1.ResultVerdictFailed, v1alpha1.ResultPhaseCompleted, step)
+	if err := e.Backend.Result(e.ChaosDetails, e.ResultDetails, resultStateEOT); err != nil {
+		log.ErrorWithValues(fmt.Sprintf("failed to record failure: %v", err), map[string]interface{}{
+			"experiment": e.ChaosDetails.ExperimentName,
+		})
+	}
+
+	msg := fmt.Sprintf("experiment: %s, Result: %s", e.ChaosDetails.ExperimentName, e.ResultDetails.Verdict)
+	e.updateResult(types.FailVerdict, msg, eventTypeWarning)
+	if e.ChaosDetails.EngineName != "" {
+		e.updateEngine(types.Summary, msg, eventTypeWarning)
+	}
+}
+
+// Record the aborted experiment in the chaos result and report the stopped verdict.
+func (e *Experiment) recordAbort() {
+	log.Info("[Chaos]: Chaos Experiment Abortion started because of terminated signal received")
+
+	types.SetResultAfterCompletion(e.ResultDetails, v1alpha1.ResultVerdictStopped, v1alpha1.ResultPhaseStopped, "Chaos injection stopped!")
+	if err := e.Backend.Result(e.ChaosDetails, e.ResultDetails, resultStateEOT); err != nil {
+		log.ErrorWithValues(fmt.Sprintf("failed to record abort: %v", err), map[string]interface{}{
+			"experiment": e.ChaosDetails.ExperimentName,
+		})
+	}
+
+	msg := e.ChaosDetails.ExperimentName + " experiment has been aborted"
+	if e.ChaosDetails.EngineName != "" {
+		e.updateEngine(types.Summary, msg, eventTypeWarning)
+	}
+	e.updateResult(types.AbortVerdict, msg, eventTypeWarning)
+}
+
+// Generate an event for the engine when running in engine context, otherwise for the chaos result. Engine events with
+// the same reason are updated, so they can be generated repeatedly.
+func (e *Experiment) notify(reason, msg, eventType string) {
+	if e.ChaosDetails.EngineName != "" {
+		e.updateEngine(reason, msg, eventType)
+	} else {
+		e.updateResult(reason, msg, eventType)
+	}
+}
+
+func (e *Experiment) updateResult(reason, msg, eventType string) {
 	types.SetResultEventAttributes(e.EventDetails, reason, msg, eventType, e.ResultDetails)
-	events.GenerateEvents(e.EventDetails, e.Clients, e.ChaosDetails, "ChaosResult")
+	e.Backend.Event(e.ChaosDetails, e.EventDetails, eventKindResult)
 }
 
-func (e Experiment) updateEngine(reason, msg, eventType string) {
+func (e *Experiment) updateEngine(reason, msg, eventType string) {
 	types.SetEngineEventAttributes(e.EventDetails, reason, msg, eventType, e.ChaosDetails)
-	events.GenerateEvents(e.EventDetails, e.Clients, e.ChaosDetails, "ChaosEngine")
+	e.Backend.Event(e.ChaosDetails, e.EventDetails, eventKindEngine)
 }
 
-// Run function with proper error handling.
-func (e Experiment) run(step string, f func(context.Context) error) error {
+// Run function with proper error handling. The function is not run, if the context is already done. If the step fails
+// in the chaos or post-chaos phase, changes are reverted before the failure is recorded, so the recorded result
+// includes the reverted targets and any revert error.
+func (e *Experiment) run(ctx context.Context, step string, f func(context.Context) error) error {
 	log.InfoWithValues(fmt.Sprintf("[Step]: %s", step), map[string]interface{}{
 		"experiment": e.ChaosDetails.ExperimentName,
 	})
 
-	err := f(context.Background())
+	err := ctx.Err()
+	if err == nil {
+		err = f(ctx)
+	}
+
 	if err != nil {
 		msg := fmt.Sprintf("failed to %s: %v", step, err)
 		log.ErrorWithValues(msg, map[string]interface{}{
 			"experiment": e.ChaosDetails.ExperimentName,
 		})
-		result.RecordAfterFailure(e.ChaosDetails, e.ResultDetails, step, e.Clients, e.EventDetails)
+
+		// An aborted experiment is reverted and recorded as stopped once Run returns.
+		if atomic.LoadInt32(&e.aborted) != 0 {
+			return errors.New(msg)
+		}
+
+		if e.revertOnFailure {
+			if revertErr := e.Revert(); revertErr != nil {
+				msg = fmt.Sprintf("%s (revert: %v)", msg, revertErr)
+				step = fmt.Sprintf("%s (revert: %v)", step, revertErr)
+			}
+		}
+
+		e.recordFailure(step)
 		return errors.New(msg)
 	}
 
@@ -233,7 +680,7 @@ func (e Experiment) run(step string, f func(context.Context) error) error {
 }
 
 // Run function if in engine context.
-func (e Experiment) runIfInEngineContext(step string, f func(context.Context) error) error {
+func (e *Experiment) runIfInEngineContext(ctx context.Context, step string, f func(context.Context) error) error {
 	if e.ChaosDetails.EngineName == "" {
 		log.InfoWithValues(fmt.Sprintf("[Skip]: %s (not running in engine context)", step), map[string]interface{}{
 			"experiment": e.ChaosDetails.ExperimentName,
@@ -241,5 +688,5 @@ func (e Experiment) runIfInEngineContext(step string, f func(context.Context) er
 		return nil
 	}
 
-	return e.run(step, f)
+	return e.run(ctx, step, f)
 }
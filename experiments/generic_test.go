@@ -0,0 +1,439 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jaconi-io/litmus/environment"
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type testDetails struct {
+	environment.ExperimentDetails
+}
+
+// engineObjects are the objects created by the chaos operator before the experiment is run: the engine, the
+// experiment and the pod running the experiment.
+func engineObjects() []runtime.Object {
+	return []runtime.Object{
+		&v1alpha1.ChaosEngine{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "ChaosEngine"},
+			ObjectMeta: metav1.ObjectMeta{Name: "engine", Namespace: "litmus"},
+			Spec: v1alpha1.ChaosEngineSpec{
+				Experiments: []v1alpha1.ExperimentList{{Name: "test"}},
+			},
+		},
+		&v1alpha1.ChaosExperiment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "ChaosExperiment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "litmus"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-runner", Namespace: "litmus", Labels: map[string]string{"job-name": "test"}},
+		},
+	}
+}
+
+// newTestExperiment creates an experiment using the fake API server. The environment variables are set for the
+// duration of the test. Litmus waits two seconds after each chaos result update, so these tests are skipped in short
+// mode.
+func newTestExperiment(t *testing.T, fake *fakeLitmus, env map[string]string) *Experiment {
+	if testing.Short() {
+		t.Skip("litmus waits after each chaos result update")
+	}
+
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	experiment, err := NewExperiment("test", Options{Clients: fake.clients()}, &testDetails{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return experiment
+}
+
+// engineEnv is the environment of an experiment run by the chaos operator.
+var engineEnv = map[string]string{
+	"CHAOS_ENGINE":    "engine",
+	"CHAOS_NAMESPACE": "litmus",
+	"CHAOS_UID":       "1234",
+	"POD_NAME":        "test-runner",
+}
+
+func TestRun(t *testing.T) {
+	fake := newFakeLitmus(t, engineObjects()...)
+	experiment := newTestExperiment(t, fake, engineEnv)
+
+	err := experiment.Run(func(context.Context) error {
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"ChaosResult Awaited: experiment: test, Result: Awaited",
+		"ChaosEngine PreChaosCheck: AUT: Running",
+		`ChaosEngine ChaosInject: injecting chaos "test"`,
+		"ChaosEngine PostChaosCheck: AUT: Running",
+		"ChaosResult Pass: experiment: test, Result: Pass",
+		`ChaosEngine Summary: experiment "test" passed`,
+	}, fake.events())
+	assert.Equal(t, []string{"Running/Awaited", "Completed/Pass"}, fake.results())
+
+	result := fake.result("litmus", "engine-test")
+	assert.Equal(t, "engine", result.Spec.EngineName)
+	assert.Equal(t, "test", result.Spec.ExperimentName)
+	assert.Equal(t, 1, result.Status.History.PassedRuns)
+	assert.Equal(t, map[string]string{"job-name": "test", "chaosUID": "1234"}, result.Labels)
+}
+
+func TestRunWithoutEngine(t *testing.T) {
+	fake := newFakeLitmus(t)
+	experiment := newTestExperiment(t, fake, map[string]string{"CHAOS_NAMESPACE": "litmus"})
+
+	err := experiment.Run(func(context.Context) error {
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"ChaosResult Awaited: experiment: test, Result: Awaited",
+		"ChaosResult Pass: experiment: test, Result: Pass",
+	}, fake.events())
+	assert.Equal(t, []string{"Running/Awaited", "Completed/Pass"}, fake.results())
+}
+
+func TestRunFailure(t *testing.T) {
+	fake := newFakeLitmus(t, engineObjects()...)
+	experiment := newTestExperiment(t, fake, engineEnv)
+
+	var reverted bool
+	err := experiment.Run(func(context.Context) error {
+		experiment.AddRevert("revert", func(context.Context) error {
+			reverted = true
+			return nil
+		})
+
+		return errors.New("boom")
+	})
+	assert.EqualError(t, err, "failed to chaos: boom")
+	assert.True(t, reverted)
+
+	assert.Equal(t, []string{
+		"ChaosResult Awaited: experiment: test, Result: Awaited",
+		"ChaosEngine PreChaosCheck: AUT: Running",
+		`ChaosEngine ChaosInject: injecting chaos "test"`,
+		"ChaosResult Fail: experiment: test, Result: Fail",
+		"ChaosEngine Summary: experiment: test, Result: Fail",
+	}, fake.events())
+	assert.Equal(t, []string{"Running/Awaited", "Completed/Fail"}, fake.results())
+
+	result := fake.result("litmus", "engine-test")
+	assert.Equal(t, "chaos", result.Status.ExperimentStatus.FailStep)
+	assert.Equal(t, 1, result.Status.History.FailedRuns)
+}
+
+func TestRunFailureRevert(t *testing.T) {
+	experiment, backend := newLocalExperiment(t, nil)
+
+	err := experiment.Run(func(context.Context) error {
+		experiment.SetTarget("vm-1", targetKindInstance, targetStatusInjected)
+		experiment.AddRevert("start vm-1", func(context.Context) error {
+			experiment.SetTarget("vm-1", targetKindInstance, targetStatusReverted)
+			return nil
+		})
+		experiment.AddRevert("start vm-2", func(context.Context) error {
+			return errors.New("boom")
+		})
+
+		return errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed to chaos: failed (revert: failed to start vm-2: boom)")
+
+	// The result is recorded after reverting.
+	assert.Equal(t, v1alpha1.ResultVerdictFailed, backend.result.Verdict)
+	assert.Equal(t, "chaos (revert: failed to start vm-2: boom)", backend.result.FailStep)
+	assert.Equal(t, map[string]string{"vm-1": targetStatusReverted}, targetStatus(backend.result))
+}
+
+func TestRunTimeout(t *testing.T) {
+	fake := newFakeLitmus(t)
+	experiment := newTestExperiment(t, fake, map[string]string{
+		"CHAOS_NAMESPACE":    "litmus",
+		"EXPERIMENT_TIMEOUT": "5s",
+	})
+
+	err := experiment.Run(func(ctx context.Context) error {
+		return sleep(ctx, time.Minute)
+	})
+	assert.EqualError(t, err, "failed to chaos: context deadline exceeded")
+
+	assert.Equal(t, []string{"Running/Awaited", "Completed/Fail"}, fake.results())
+	assert.Equal(t, "chaos", fake.result("litmus", "test").Status.ExperimentStatus.FailStep)
+}
+
+// TestRunOnChaosProbes runs the experiment with an OnChaos probe. Run with -race to detect data races between the
+// experiment and the probes. The probe goroutines of litmus share state with each other, so the probe is delayed
+// beyond the end of the test and only the probe setup and the evaluation of the verdict run concurrently with the
+// experiment.
+func TestRunOnChaosProbes(t *testing.T) {
+	objects := engineObjects()
+	objects[0].(*v1alpha1.ChaosEngine).Spec.Experiments[0].Spec.Probe = []v1alpha1.ProbeAttributes{{
+		Name: "healthy",
+		Type: "httpProbe",
+		Mode: "OnChaos",
+		HTTPProbeInputs: v1alpha1.HTTPProbeInputs{
+			URL:             "http://localhost",
+			Method:          v1alpha1.HTTPMethod{Get: v1alpha1.GetMethod{Criteria: "==", ResponseCode: "200"}},
+			ResponseTimeout: 1000,
+		},
+		RunProperties: v1alpha1.RunProperty{
+			InitialDelaySeconds:  3600,
+			Interval:             1,
+			ProbePollingInterval: 1,
+			ProbeTimeout:         1,
+			Retry:                1,
+		},
+	}}
+
+	fake := newFakeLitmus(t, objects...)
+	env := map[string]string{"TOTAL_CHAOS_DURATION": "2"}
+	for key, value := range engineEnv {
+		env[key] = value
+	}
+	experiment := newTestExperiment(t, fake, env)
+
+	start := time.Now()
+	err := experiment.Run(func(context.Context) error {
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// The chaos phase lasts for the chaos duration, so the probe covers it.
+	assert.GreaterOrEqual(t, time.Since(start), 2*time.Second)
+	assert.Equal(t, []string{"Running/Awaited", "Completed/Pass"}, fake.results())
+	assert.Equal(t, map[string]string{"OnChaos": "Passed 👍 "}, experiment.ResultDetails.ProbeDetails[0].Status)
+}
+
+func TestRunAbort(t *testing.T) {
+	experiment, backend := newLocalExperiment(t, nil)
+
+	var reverted, returned bool
+	err := experiment.Run(func(ctx context.Context) error {
+		experiment.AddRevert("revert", func(context.Context) error {
+			// Changes are only reverted once the chaos function returned.
+			reverted = returned
+			return nil
+		})
+
+		if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+			return err
+		}
+
+		<-ctx.Done()
+		returned = true
+		return ctx.Err()
+	})
+	assert.EqualError(t, err, "experiment aborted: failed to chaos: context canceled")
+	assert.True(t, reverted)
+
+	assert.Equal(t, v1alpha1.ResultVerdictStopped, backend.result.Verdict)
+	assert.Equal(t, []string{"Awaited", "Abort"}, reasons(backend))
+}
+
+func TestRevertRetry(t *testing.T) {
+	experiment, _ := newLocalExperiment(t, nil)
+
+	var calls []string
+	experiment.AddRevert("first", func(ctx context.Context) error {
+		calls = append(calls, "first")
+		return ctx.Err()
+	})
+	experiment.AddRevert("second", func(context.Context) error {
+		calls = append(calls, "second")
+		if len(calls) == 1 {
+			return errors.New("boom")
+		}
+
+		return nil
+	})
+
+	// The failed action stays registered and is retried.
+	assert.EqualError(t, experiment.Revert(), "failed to second: boom")
+	assert.NoError(t, experiment.Revert())
+	assert.NoError(t, experiment.Revert())
+	assert.Equal(t, []string{"second", "first", "second"}, calls)
+}
+
+func TestNewExperimentEngineWithoutCluster(t *testing.T) {
+	t.Setenv("CHAOS_ENGINE", "engine")
+
+	_, err := NewExperiment("test", Options{Backend: &LocalBackend{Dir: t.TempDir()}}, &testDetails{})
+	assert.EqualError(t, err, "running in the context of an engine (CHAOS_ENGINE) requires a Kubernetes cluster")
+}
+
+// newLocalExperiment creates an experiment using the local backend. The environment variables are set for the
+// duration of the test.
+func newLocalExperiment(t *testing.T, env map[string]string) (*Experiment, *LocalBackend) {
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	backend := &LocalBackend{Dir: t.TempDir()}
+	experiment, err := NewExperiment("test", Options{Backend: backend}, &testDetails{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return experiment, backend
+}
+
+// reasons returns the reasons of the events recorded by the local backend.
+func reasons(backend *LocalBackend) []string {
+	reasons := make([]string, len(backend.result.Events))
+	for i, event := range backend.result.Events {
+		reasons[i] = event.Reason
+	}
+
+	return reasons
+}
+
+func TestRunRampTime(t *testing.T) {
+	experiment, backend := newLocalExperiment(t, map[string]string{"RAMP_TIME": "10ms"})
+
+	err := experiment.Run(func(context.Context) error {
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"Awaited", "RampStart", "RampEnd", "RampStart", "RampEnd", "Pass"}, reasons(backend))
+	assert.Equal(t, "waiting for the 10ms ramp time before chaos", backend.result.Events[1].Message)
+	assert.Equal(t, "the 10ms ramp time after chaos elapsed", backend.result.Events[4].Message)
+}
+
+func TestRampCancelled(t *testing.T) {
+	experiment, backend := newLocalExperiment(t, map[string]string{"RAMP_TIME": "1h"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	err := experiment.ramp(ctx, "before chaos")
+	assert.EqualError(t, err, "failed to ramp time before chaos: context canceled")
+	assert.Equal(t, []string{"RampStart", "Fail"}, reasons(backend))
+}
+
+func TestIterate(t *testing.T) {
+	experiment, backend := newLocalExperiment(t, map[string]string{
+		"CHAOS_INTERVAL":       "10ms",
+		"TOTAL_CHAOS_DURATION": "30ms",
+	})
+
+	var iterations []int
+	err := experiment.Iterate(context.Background(), func(ctx context.Context, iteration int) error {
+		iterations = append(iterations, iteration)
+		if iteration == 2 {
+			return errors.New("boom")
+		}
+
+		return nil
+	})
+	assert.EqualError(t, err, "iteration 2: boom")
+	assert.Equal(t, []int{1, 2, 3}, iterations)
+
+	assert.Equal(t, []v1alpha1.TargetDetails{
+		{Name: "iteration-1", Kind: targetKindIteration, ChaosStatus: iterationStatusPassed},
+		{Name: "iteration-2", Kind: targetKindIteration, ChaosStatus: iterationStatusFailed},
+		{Name: "iteration-3", Kind: targetKindIteration, ChaosStatus: iterationStatusPassed},
+	}, experiment.ChaosDetails.Targets)
+	assert.Equal(t, []string{"ChaosIteration", "ChaosIteration", "ChaosIteration"}, reasons(backend))
+	assert.Equal(t, "iteration 2 of 3 failed: boom", backend.result.Events[1].Message)
+}
+
+func TestIterateTargets(t *testing.T) {
+	experiment, backend := newLocalExperiment(t, map[string]string{
+		"CHAOS_INTERVAL":       "100ms",
+		"TOTAL_CHAOS_DURATION": "200ms",
+	})
+
+	err := experiment.Iterate(context.Background(), func(ctx context.Context, iteration int) error {
+		target := fmt.Sprintf("zone-a/vm-%d", iteration)
+		experiment.SetTarget(target, targetKindInstance, targetStatusInjected)
+		experiment.SetTarget(target, targetKindInstance, targetStatusReverted)
+		if iteration == 2 {
+			return errors.New("boom")
+		}
+
+		return nil
+	})
+	assert.EqualError(t, err, "iteration 2: boom")
+
+	// Each iteration reports the instances it targeted, although the statuses are recorded once per instance.
+	assert.Equal(t, []string{
+		"iteration 1 of 2 passed (targets: zone-a/vm-1)",
+		"iteration 2 of 2 failed (targets: zone-a/vm-2): boom",
+	}, []string{backend.result.Events[0].Message, backend.result.Events[1].Message})
+	assert.Equal(t, []v1alpha1.TargetDetails{
+		{Name: "zone-a/vm-1", Kind: targetKindInstance, ChaosStatus: targetStatusReverted},
+		{Name: "iteration-1", Kind: targetKindIteration, ChaosStatus: iterationStatusPassed},
+		{Name: "zone-a/vm-2", Kind: targetKindInstance, ChaosStatus: targetStatusReverted},
+		{Name: "iteration-2", Kind: targetKindIteration, ChaosStatus: iterationStatusFailed},
+	}, experiment.ChaosDetails.Targets)
+}
+
+func TestIterateOnce(t *testing.T) {
+	experiment, _ := newLocalExperiment(t, map[string]string{"TOTAL_CHAOS_DURATION": "30ms"})
+
+	var iterations []int
+	err := experiment.Iterate(context.Background(), func(ctx context.Context, iteration int) error {
+		iterations = append(iterations, iteration)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, iterations)
+}
+
+func TestIterateSkipsIntervals(t *testing.T) {
+	experiment, _ := newLocalExperiment(t, map[string]string{
+		"CHAOS_INTERVAL":       "100ms",
+		"TOTAL_CHAOS_DURATION": "400ms",
+	})
+
+	var iterations []int
+	start := time.Now()
+	err := experiment.Iterate(context.Background(), func(ctx context.Context, iteration int) error {
+		iterations = append(iterations, iteration)
+		if iteration == 1 {
+			time.Sleep(150 * time.Millisecond)
+		}
+
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// The second interval started while the first iteration was running. The iterations stay on schedule.
+	assert.Equal(t, []int{1, 3, 4}, iterations)
+	assert.Less(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestIterateCancelled(t *testing.T) {
+	experiment, _ := newLocalExperiment(t, map[string]string{
+		"CHAOS_INTERVAL":       "1h",
+		"TOTAL_CHAOS_DURATION": "2h",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := experiment.Iterate(ctx, func(ctx context.Context, iteration int) error {
+		cancel()
+		return nil
+	})
+	assert.EqualError(t, err, "iteration 2: context canceled")
+}
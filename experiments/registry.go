@@ -0,0 +1,73 @@
+package experiments
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Definition describes an experiment.
+type Definition struct {
+	// Name of the experiment as passed to the --experiment flag.
+	Name string
+
+	// Description is a short, human readable summary of the experiment.
+	Description string
+
+	// Details are the (empty) experiment specific details. They must embed environment.ExperimentDetails and are used
+	// to document the configuration of the experiment.
+	Details interface{}
+
+	// Defaults override the default values of environment variables in the generated charts.
+	Defaults map[string]string
+
+	// Run executes the experiment.
+	Run func(Options) error
+}
+
+// Registry holds experiment definitions by name.
+type Registry struct {
+	definitions map[string]Definition
+}
+
+// Experiments contains all experiments provided by this package. Experiments register themselves on initialization.
+var Experiments = &Registry{}
+
+// Register an experiment definition. Register panics, if an experiment with the same name has already been registered.
+func (r *Registry) Register(d Definition) {
+	if r.definitions == nil {
+		r.definitions = map[string]Definition{}
+	}
+
+	if _, ok := r.definitions[d.Name]; ok {
+		panic(fmt.Sprintf("experiment %q registered twice", d.Name))
+	}
+
+	r.definitions[d.Name] = d
+}
+
+// Lookup an experiment definition by name.
+func (r *Registry) Lookup(name string) (Definition, bool) {
+	d, ok := r.definitions[name]
+	return d, ok
+}
+
+// Names of all registered experiments in alphabetical order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.definitions))
+	for name := range r.definitions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Definitions of all registered experiments in alphabetical order.
+func (r *Registry) Definitions() []Definition {
+	definitions := make([]Definition, 0, len(r.definitions))
+	for _, name := range r.Names() {
+		definitions = append(definitions, r.definitions[name])
+	}
+
+	return definitions
+}
@@ -8,6 +8,7 @@ require (
 	github.com/litmuschaos/litmus-go v0.0.0-20220513035354-111534cf321d
 	github.com/stretchr/testify v1.7.1
 	google.golang.org/api v0.81.0
+	k8s.io/api v0.22.2
 	k8s.io/apimachinery v0.21.1
 	k8s.io/client-go v12.0.0+incompatible
 )
@@ -6,47 +6,113 @@ import (
 	"os"
 	"strings"
 
+	"github.com/jaconi-io/litmus/chart"
 	"github.com/jaconi-io/litmus/experiments"
 
+	litmus "github.com/litmuschaos/chaos-operator/pkg/client/clientset/versioned/typed/litmuschaos/v1alpha1"
 	"github.com/litmuschaos/litmus-go/pkg/clients"
 	"github.com/litmuschaos/litmus-go/pkg/log"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 )
 
-var exps = map[string]func(clients.ClientSets) error{
-	"gcp-vm-stop":    experiments.GCPVMStop,
-	"gcp-vm-restart": experiments.GCPVMRestart,
-}
-
 func main() {
-	// Create a slice of experiment names.
-	keys := make([]string, len(exps))
-	i := 0
-	for key := range exps {
-		keys[i] = key
-		i++
+	// Generate the charts for all experiments (litmus generate charts [-dir <dir>]).
+	if len(os.Args) > 2 && os.Args[1] == "generate" && os.Args[2] == "charts" {
+		generateCharts(os.Args[3:])
+		return
 	}
 
 	// Get the experiment name from a command line flag.
-	experiment := flag.String("experiment", keys[0], fmt.Sprintf("name of the experiment [%s]", strings.Join(keys, ", ")))
+	experiment := flag.String("experiment", "", fmt.Sprintf("name of the experiment [%s]", strings.Join(experiments.Experiments.Names(), ", ")))
+	kubeconfig := flag.String("kubeconfig", "", "absolute path to the kubeconfig file (defaults to the in-cluster configuration)")
+	local := flag.Bool("local", false, "run without a Kubernetes cluster and write chaos results to the results directory")
+	results := flag.String("results", "results", "directory to write chaos results to in local mode")
+	dryRun := flag.Bool("dry-run", false, "resolve and check the targets without causing chaos (same as DRY_RUN=true)")
+	flag.Usage = usage
+	flag.Parse()
 
-	clients := clients.ClientSets{}
-	if err := clients.GenerateClientSetFromKubeConfig(); err != nil {
-		log.Fatalf("failed to generate clients from kubernetes configuration: %v", err)
-		return
+	opts := experiments.Options{DryRun: *dryRun}
+	if *local {
+		opts.Backend = &experiments.LocalBackend{Dir: *results}
+	} else {
+		clients, err := newClients(*kubeconfig)
+		if err != nil {
+			log.Fatalf("failed to generate clients from kubernetes configuration: %v", err)
+			return
+		}
+
+		opts.Clients = clients
 	}
 
 	values := map[string]interface{}{"experiment": *experiment}
-	if f, ok := exps[*experiment]; ok {
+	if d, ok := experiments.Experiments.Lookup(*experiment); ok {
 		log.InfoWithValues("exection started", values)
-		err := f(clients)
+		err := d.Run(opts)
 		if err != nil {
 			log.ErrorWithValues(fmt.Sprintf("execution failed: %v", err), values)
 			os.Exit(1)
 		}
 	} else {
-		log.ErrorWithValues(fmt.Sprintf("unknown experiment %q; you might be using the wrong image", *experiment), values)
+		log.ErrorWithValues(fmt.Sprintf("unknown experiment %q; you might be using the wrong image (available experiments: %s)", *experiment, strings.Join(experiments.Experiments.Names(), ", ")), values)
 		os.Exit(2)
 	}
 }
+
+// Print usage information including the available experiments.
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+	flag.PrintDefaults()
+
+	fmt.Fprintf(out, "\nTo generate the charts for all experiments, run:\n  %s generate charts [-dir <dir>]\n", os.Args[0])
+
+	fmt.Fprintln(out, "\nExperiments:")
+	for _, d := range experiments.Experiments.Definitions() {
+		fmt.Fprintf(out, "  %s\n    \t%s\n", d.Name, d.Description)
+	}
+}
+
+// Generate the charts for all experiments.
+func generateCharts(args []string) {
+	flags := flag.NewFlagSet("generate charts", flag.ExitOnError)
+	dir := flags.String("dir", "charts/gcp", "directory to write the charts to")
+	_ = flags.Parse(args)
+
+	if err := chart.Generate(*dir, experiments.Experiments.Definitions()); err != nil {
+		log.Fatalf("failed to generate charts: %v", err)
+	}
+}
+
+// Generate the Kubernetes and Litmus clients. The in-cluster configuration is used, if no kubeconfig is given.
+func newClients(kubeconfig string) (clients.ClientSets, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return clients.ClientSets{}, err
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return clients.ClientSets{}, err
+	}
+
+	litmusClient, err := litmus.NewForConfig(config)
+	if err != nil {
+		return clients.ClientSets{}, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return clients.ClientSets{}, err
+	}
+
+	return clients.ClientSets{
+		KubeClient:    kubeClient,
+		LitmusClient:  litmusClient,
+		KubeConfig:    config,
+		DynamicClient: dynamicClient,
+	}, nil
+}